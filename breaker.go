@@ -0,0 +1,181 @@
+package uim
+
+import (
+	"sync"
+	"time"
+)
+
+// 熔断器状态
+type CircuitState int
+
+const (
+	CircuitStateClosed   CircuitState = iota // 关闭，请求正常通过
+	CircuitStateOpen                         // 打开，请求直接失败
+	CircuitStateHalfOpen                     // 半开，允许少量探测请求通过
+)
+
+func (state CircuitState) String() string {
+	switch state {
+	case CircuitStateClosed:
+		return "closed"
+	case CircuitStateOpen:
+		return "open"
+	case CircuitStateHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// 熔断器配置
+type CircuitSettings struct {
+	Window           time.Duration               // 统计失败率的时间窗口，默认 10s
+	MinRequests      int32                       // 时间窗口内请求数达到该值后才会熔断，默认 10
+	FailureRatio     float64                     // 失败率达到该值时熔断，默认 0.5
+	CoolDown         time.Duration               // 熔断后经过该时间进入半开状态，默认 30s
+	HalfOpenRequests int32                       // 半开状态允许的探测请求数，全部成功后关闭熔断，默认 1
+	OnStateChange    func(from, to CircuitState) // 状态变化回调
+}
+
+type circuitBreaker struct {
+	settings         CircuitSettings
+	lock             sync.Mutex
+	state            CircuitState
+	windowStart      time.Time
+	requests         int32
+	failures         int32
+	openedAt         time.Time
+	halfOpenRequests int32
+	halfOpenSuccess  int32
+}
+
+func newCircuitBreaker(settings CircuitSettings) *circuitBreaker {
+	if settings.Window <= 0 {
+		settings.Window = 10 * time.Second
+	}
+	if settings.MinRequests <= 0 {
+		settings.MinRequests = 10
+	}
+	if settings.FailureRatio <= 0 {
+		settings.FailureRatio = 0.5
+	}
+	if settings.CoolDown <= 0 {
+		settings.CoolDown = 30 * time.Second
+	}
+	if settings.HalfOpenRequests <= 0 {
+		settings.HalfOpenRequests = 1
+	}
+	return &circuitBreaker{
+		settings:    settings,
+		windowStart: time.Now(),
+	}
+}
+
+func (breaker *circuitBreaker) State() CircuitState {
+	breaker.lock.Lock()
+	defer breaker.lock.Unlock()
+	if breaker.state == CircuitStateOpen && time.Since(breaker.openedAt) >= breaker.settings.CoolDown {
+		return CircuitStateHalfOpen
+	}
+	return breaker.state
+}
+
+// 请求是否可以通过
+func (breaker *circuitBreaker) allow() bool {
+	breaker.lock.Lock()
+	from := breaker.state
+	allowed := true
+	switch breaker.state {
+	case CircuitStateOpen:
+		if time.Since(breaker.openedAt) < breaker.settings.CoolDown {
+			allowed = false
+			break
+		}
+		breaker.setState(CircuitStateHalfOpen)
+		breaker.halfOpenRequests = 1
+	case CircuitStateHalfOpen:
+		if breaker.halfOpenRequests >= breaker.settings.HalfOpenRequests {
+			allowed = false
+			break
+		}
+		breaker.halfOpenRequests++
+	}
+	to := breaker.state
+	breaker.lock.Unlock()
+
+	breaker.notify(from, to)
+	return allowed
+}
+
+// 通过 allow 的请求没有发出时归还半开状态的试探名额
+func (breaker *circuitBreaker) release() {
+	breaker.lock.Lock()
+	if breaker.state == CircuitStateHalfOpen && breaker.halfOpenRequests > 0 {
+		breaker.halfOpenRequests--
+	}
+	breaker.lock.Unlock()
+}
+
+// 记录请求结果
+func (breaker *circuitBreaker) record(success bool) {
+	breaker.lock.Lock()
+	from := breaker.state
+	now := time.Now()
+	switch breaker.state {
+	case CircuitStateClosed:
+		if now.Sub(breaker.windowStart) >= breaker.settings.Window {
+			breaker.windowStart = now
+			breaker.requests = 0
+			breaker.failures = 0
+		}
+		breaker.requests++
+		if !success {
+			breaker.failures++
+		}
+		if breaker.requests >= breaker.settings.MinRequests &&
+			float64(breaker.failures)/float64(breaker.requests) >= breaker.settings.FailureRatio {
+			breaker.setState(CircuitStateOpen)
+		}
+	case CircuitStateHalfOpen:
+		if !success {
+			breaker.setState(CircuitStateOpen)
+			break
+		}
+		breaker.halfOpenSuccess++
+		if breaker.halfOpenSuccess >= breaker.settings.HalfOpenRequests {
+			breaker.setState(CircuitStateClosed)
+		}
+	}
+	to := breaker.state
+	breaker.lock.Unlock()
+
+	breaker.notify(from, to)
+}
+
+func (breaker *circuitBreaker) setState(state CircuitState) {
+	breaker.state = state
+	breaker.halfOpenRequests = 0
+	breaker.halfOpenSuccess = 0
+	switch state {
+	case CircuitStateOpen:
+		breaker.openedAt = time.Now()
+	case CircuitStateClosed:
+		breaker.windowStart = time.Now()
+		breaker.requests = 0
+		breaker.failures = 0
+	}
+}
+
+func (breaker *circuitBreaker) notify(from, to CircuitState) {
+	if from != to && breaker.settings.OnStateChange != nil {
+		breaker.settings.OnStateChange(from, to)
+	}
+}
+
+// 查询调用 UIM 的熔断器状态，未设置熔断器时总是关闭状态
+func (client *Client) CircuitState() CircuitState {
+	if client.breaker == nil {
+		return CircuitStateClosed
+	}
+	return client.breaker.State()
+}
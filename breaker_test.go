@@ -0,0 +1,94 @@
+package uim
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var healthy int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	changes := make(chan [2]CircuitState, 10)
+	client := NewClient(
+		WithAuthorization(false),
+		WithEventSource("provider.source/test/test"),
+		WithBaseUrl(server.URL),
+		WithCircuitBreaker(CircuitSettings{
+			MinRequests:  3,
+			FailureRatio: 0.5,
+			CoolDown:     50 * time.Millisecond,
+			OnStateChange: func(from, to CircuitState) {
+				changes <- [2]CircuitState{from, to}
+			},
+		}),
+	)
+
+	message := &Message{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u1", Type: MessageTypeText, Text: "hello"}
+	for i := 0; i < 3; i++ {
+		err := client.SendEvent(ProviderEventNewMessage, message)
+		assert.Equal(t, DefaultServerErrorCode, err.(Error).ErrorCode())
+	}
+	assert.Equal(t, CircuitStateOpen, client.CircuitState())
+	assert.Equal(t, [2]CircuitState{CircuitStateClosed, CircuitStateOpen}, <-changes)
+
+	err := client.SendEvent(ProviderEventNewMessage, message)
+	assert.Equal(t, CircuitOpenErrorCode, err.(*ClientError).ErrorCode())
+
+	time.Sleep(60 * time.Millisecond)
+	atomic.StoreInt32(&healthy, 1)
+	err = client.SendEvent(ProviderEventNewMessage, message)
+	assert.Nil(t, err)
+	assert.Equal(t, CircuitStateClosed, client.CircuitState())
+	assert.Equal(t, [2]CircuitState{CircuitStateOpen, CircuitStateHalfOpen}, <-changes)
+	assert.Equal(t, [2]CircuitState{CircuitStateHalfOpen, CircuitStateClosed}, <-changes)
+}
+
+func TestCircuitOpenSkipsRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAuthorization(false),
+		WithEventSource("provider.source/test/test"),
+		WithBaseUrl(server.URL),
+		WithRateLimit(1, 1),
+		WithCircuitBreaker(CircuitSettings{MinRequests: 1, FailureRatio: 0.5, CoolDown: time.Minute}),
+	)
+	message := &Message{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u1", Type: MessageTypeText, Text: "hello"}
+	err := client.SendEvent(ProviderEventNewMessage, message)
+	assert.Equal(t, DefaultServerErrorCode, err.(Error).ErrorCode())
+
+	// 熔断时立即返回，不等待限流
+	start := time.Now()
+	err = client.SendEvent(ProviderEventNewMessage, message)
+	assert.Equal(t, CircuitOpenErrorCode, err.(*ClientError).ErrorCode())
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestCircuitBreakerRelease(t *testing.T) {
+	breaker := newCircuitBreaker(CircuitSettings{MinRequests: 1, FailureRatio: 0.5, CoolDown: time.Millisecond})
+	breaker.record(false)
+	assert.Equal(t, CircuitStateOpen, breaker.State())
+
+	time.Sleep(5 * time.Millisecond)
+	assert.True(t, breaker.allow())
+	assert.False(t, breaker.allow())
+	// 试探请求没有发出时归还名额
+	breaker.release()
+	assert.True(t, breaker.allow())
+}
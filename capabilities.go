@@ -0,0 +1,39 @@
+package uim
+
+import (
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// 没有注册处理函数时 EventHandler 自动回复的指令
+var builtinCommands = map[string]bool{
+	UIMCommandGetCapabilities: true,
+	UIMCommandPing:            true,
+}
+
+// 是否是 EventHandler 自动回复的指令，注册通用的处理函数时应跳过，否则会覆盖自动回复
+func IsBuiltinCommand(eventType string) bool {
+	return builtinCommands[eventType]
+}
+
+// 查找事件的处理函数，没有注册 uim.get_capabilities、uim.ping 的处理函数时自动回复，调用方需要持有 eventLock
+func (c *Client) lookupEventHandler(eventType string) (EventHandler, bool) {
+	if handler, ok := c.eventHandlers[eventType]; ok {
+		return handler, true
+	}
+	switch eventType {
+	case UIMCommandGetCapabilities:
+		return c.getCapabilities, true
+	case UIMCommandPing:
+		return c.ping, true
+	}
+	return nil, false
+}
+
+// 回复声明的能力和注册了处理函数的事件、指令类型
+func (c *Client) getCapabilities(_ *cloudevents.Event) (any, error) {
+	resp := &GetCapabilitiesResponse{EventTypes: c.handledEventTypes()}
+	if c.options.Capabilities != nil {
+		resp.Capabilities = *c.options.Capabilities
+	}
+	return resp, nil
+}
@@ -0,0 +1,48 @@
+package uim
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCapabilities(t *testing.T) {
+	provider := NewClient(
+		WithEventAuthorization(false),
+		WithCapabilities(Capabilities{
+			MessageTypes:       []MessageType{MessageTypeText, MessageTypeImage},
+			MaxAttachmentSizes: map[MessageType]int64{MessageTypeImage: 10 << 20},
+			Moment:             true,
+		}),
+	)
+	HandleCommand(provider, UIMCommandSendMessage, func(_ *cloudevents.Event, _ *SendMessageRequest) (*SendMessageResponse, error) {
+		return &SendMessageResponse{}, nil
+	})
+	server := httptest.NewServer(provider.EventHandler())
+	defer server.Close()
+
+	client := NewClient(WithAuthorization(false), WithEventSource("uim/test"), WithBaseUrl(server.URL))
+	resp, err := CastCommandResponse[*GetCapabilitiesResponse](
+		client.Invoke(UIMCommandGetCapabilities, &GetCapabilitiesRequest{}, &GetCapabilitiesResponse{}),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{UIMCommandSendMessage}, resp.EventTypes)
+	assert.Equal(t, []MessageType{MessageTypeText, MessageTypeImage}, resp.MessageTypes)
+	assert.Equal(t, int64(10<<20), resp.MaxAttachmentSizes[MessageTypeImage])
+	assert.True(t, resp.Moment)
+	assert.False(t, resp.GroupManagement)
+
+	// 注册了处理函数时使用注册的处理函数
+	HandleCommand(provider, UIMCommandGetCapabilities, func(_ *cloudevents.Event, _ *GetCapabilitiesRequest) (*GetCapabilitiesResponse, error) {
+		return &GetCapabilitiesResponse{EventTypes: []string{"custom"}}, nil
+	})
+	resp, err = CastCommandResponse[*GetCapabilitiesResponse](
+		client.Invoke(UIMCommandGetCapabilities, &GetCapabilitiesRequest{}, &GetCapabilitiesResponse{}),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"custom"}, resp.EventTypes)
+	assert.Equal(t, http.StatusOK, resp.GetHttpStatus())
+}
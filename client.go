@@ -5,14 +5,18 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"log/slog"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,14 +26,10 @@ import (
 	"github.com/authok/go-jwt-middleware/v2/validator"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	gonanoid "github.com/matoous/go-nanoid/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-var debug Debug
-
-func init() {
-	debug = getDebug("sdk")
-}
-
 // Version will be replaced while build: -ldflags="-X uim.Version=x.x.x"
 var Version = "0.0.1"
 var DefaultUserAgent = fmt.Sprintf("UIMKit (%s; %s) Golang/%s Core/%s", runtime.GOOS, runtime.GOARCH, strings.Trim(runtime.Version(), "go"), Version)
@@ -39,14 +39,24 @@ type EventHandler func(*cloudevents.Event) (any, error)
 type Client struct {
 	options              *Options
 	httpClient           *http.Client
+	insecureHttpClient   *http.Client
+	loggerLock           sync.RWMutex
 	logger               *Logger
+	slog                 *slog.Logger
 	asyncTaskQueue       chan func()
 	isOpenAsync          bool
 	eventLock            sync.RWMutex
 	eventHandlers        map[string]EventHandler
-	accessTokenLock      sync.Mutex
+	accessTokenLock      sync.RWMutex
 	accessToken          string
 	accessTokenExpiresAt time.Time
+	outbox               *outboxDispatcher
+	ordering             *orderedExecutor
+	rateLimiter          *RateLimiter
+	accountRateLimiter   *RateLimiter
+	breaker              *circuitBreaker
+	metrics              Metrics
+	dumper               *wireDumper
 }
 
 func (client *Client) getHttpProxy(scheme string) (proxy *url.URL, err error) {
@@ -118,6 +128,7 @@ func (client *Client) enableAsync(routinePoolSize, maxTaskQueueSize int32) {
 				if !notClosed {
 					return
 				} else {
+					client.observeAsyncQueue()
 					task()
 				}
 			}
@@ -130,6 +141,9 @@ func (client *Client) Shutdown() {
 		close(client.asyncTaskQueue)
 	}
 	client.isOpenAsync = false
+	if client.outbox != nil {
+		client.outbox.close()
+	}
 }
 
 /**
@@ -141,6 +155,7 @@ func (client *Client) AddAsyncTask(task func()) (err error) {
 	if client.asyncTaskQueue != nil {
 		if client.isOpenAsync {
 			client.asyncTaskQueue <- task
+			client.observeAsyncQueue()
 		}
 	} else {
 		err = NewClientError(AsyncFunctionNotEnabledCode, AsyncFunctionNotEnabledMessage, nil)
@@ -149,8 +164,15 @@ func (client *Client) AddAsyncTask(task func()) (err error) {
 }
 
 func (client *Client) GetAccessToken() (string, error) {
-	if client.accessToken != "" && client.accessTokenExpiresAt.After(time.Now()) {
-		return client.accessToken, nil
+	return client.getAccessToken(context.Background())
+}
+
+func (client *Client) getAccessToken(ctx context.Context) (string, error) {
+	client.accessTokenLock.RLock()
+	accessToken, expiresAt := client.accessToken, client.accessTokenExpiresAt
+	client.accessTokenLock.RUnlock()
+	if accessToken != "" && expiresAt.After(time.Now()) {
+		return accessToken, nil
 	}
 
 	client.accessTokenLock.Lock()
@@ -160,23 +182,34 @@ func (client *Client) GetAccessToken() (string, error) {
 		return client.accessToken, nil
 	}
 
-	accessToken, expiresIn, err := client.Authorize()
+	accessToken, expiresIn, err := client.authorize(ctx)
+	client.metrics.ObserveTokenRefresh(err == nil)
 	if err != nil {
+		client.logAttrs(ctx, slog.LevelWarn, "uim authorize", slog.String("error", err.Error()))
 		return "", err
 	}
-	expiresAt := time.Now().Add(time.Duration(expiresIn-300) * time.Second)
+	client.logAttrs(ctx, slog.LevelInfo, "uim authorize", slog.Int64("expires_in", expiresIn))
+	expiresAt = time.Now().Add(time.Duration(expiresIn-300) * time.Second)
 	client.accessToken = accessToken
 	client.accessTokenExpiresAt = expiresAt
 	return accessToken, nil
 }
 
-func (client *Client) buildRequest(request Request) (httpRequest *http.Request, err error) {
+// 清除缓存的 access token，下次请求时重新获取
+func (client *Client) resetAccessToken() {
+	client.accessTokenLock.Lock()
+	client.accessToken = ""
+	client.accessTokenExpiresAt = time.Time{}
+	client.accessTokenLock.Unlock()
+}
+
+func (client *Client) buildRequest(ctx context.Context, request Request) (httpRequest *http.Request, err error) {
 	// add clientVersion
 	request.GetHeaders()["x-sdk-core-version"] = Version
 
 	// add authorization
 	if client.options.EnableAuthorization {
-		accessToken, err := client.GetAccessToken()
+		accessToken, err := client.getAccessToken(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -236,8 +269,6 @@ func (client *Client) getTimeout(request Request) (time.Duration, time.Duration)
 		readTimeout = reqReadTimeout
 	} else if client.options.ReadTimeout > 0 {
 		readTimeout = client.options.ReadTimeout
-	} else if client.httpClient.Timeout > 0 {
-		readTimeout = client.httpClient.Timeout
 	}
 
 	if reqConnectTimeout > 0 {
@@ -248,28 +279,94 @@ func (client *Client) getTimeout(request Request) (time.Duration, time.Duration)
 	return readTimeout, connectTimeout
 }
 
-func timeoutDialer(connectTimeout time.Duration) func(cxt context.Context, net, addr string) (c net.Conn, err error) {
-	return func(ctx context.Context, network, address string) (net.Conn, error) {
-		return (&net.Dialer{
-			Timeout:   connectTimeout,
-			DualStack: true,
-		}).DialContext(ctx, network, address)
-	}
+type connectTimeoutKey struct{}
+
+// 连接超时从请求的 context 中获取，使并发的请求可以使用不同的连接超时
+func dialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	connectTimeout, _ := ctx.Value(connectTimeoutKey{}).(time.Duration)
+	return (&net.Dialer{
+		Timeout:   connectTimeout,
+		DualStack: true,
+	}).DialContext(ctx, network, address)
 }
 
-func (client *Client) setTimeout(request Request) {
+// 为请求设置超时，读超时通过 context 的 deadline 实现
+func (client *Client) withTimeout(ctx context.Context, request Request, httpRequest *http.Request) (*http.Request, context.CancelFunc) {
 	readTimeout, connectTimeout := client.getTimeout(request)
-	client.httpClient.Timeout = readTimeout
-	if trans, ok := client.httpClient.Transport.(*http.Transport); ok && trans != nil {
-		trans.DialContext = timeoutDialer(connectTimeout)
-		client.httpClient.Transport = trans
-	} else if client.httpClient.Transport == nil {
-		client.httpClient.Transport = &http.Transport{
-			DialContext: timeoutDialer(connectTimeout),
+	ctx = context.WithValue(ctx, connectTimeoutKey{}, connectTimeout)
+	cancel := context.CancelFunc(func() {})
+	if readTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, readTimeout)
+	}
+	return httpRequest.WithContext(ctx), cancel
+}
+
+func (client *Client) proxy(fallback func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		proxy, err := client.getHttpProxy(req.URL.Scheme)
+		if err != nil {
+			return nil, err
+		}
+		if proxy == nil {
+			if fallback != nil {
+				return fallback(req)
+			}
+			return nil, nil
+		}
+
+		host := req.Host
+		if host == "" {
+			host = req.URL.Host
 		}
+		for _, value := range client.getNoProxy(req.URL.Scheme) {
+			if strings.HasPrefix(value, "*") {
+				value = fmt.Sprintf(".%s", value)
+			}
+			noProxyReg, err := regexp.Compile(value)
+			if err != nil {
+				return nil, err
+			}
+			if noProxyReg.MatchString(host) {
+				return nil, nil
+			}
+		}
+		return proxy, nil
 	}
 }
 
+// 创建 http 客户端，只在 NewClient 时调用，之后不再修改，可以被并发的请求共享
+func (client *Client) newHttpClient(insecure bool) *http.Client {
+	options := client.options
+	if options.Transport != nil {
+		return &http.Client{Transport: options.Transport}
+	}
+
+	var trans *http.Transport
+	if options.HttpTransport != nil {
+		trans = options.HttpTransport.Clone()
+	} else {
+		trans = &http.Transport{}
+	}
+	trans.DialContext = dialContext
+	trans.Proxy = client.proxy(trans.Proxy)
+	// Set whether to ignore certificate validation.
+	// Default InsecureSkipVerify is false.
+	if trans.TLSClientConfig != nil {
+		trans.TLSClientConfig = trans.TLSClientConfig.Clone()
+	} else {
+		trans.TLSClientConfig = &tls.Config{}
+	}
+	trans.TLSClientConfig.InsecureSkipVerify = insecure
+	return &http.Client{Transport: trans}
+}
+
+func (client *Client) getHttpClient(request Request) *http.Client {
+	if client.getHTTPSInsecure(request) {
+		return client.insecureHttpClient
+	}
+	return client.httpClient
+}
+
 func (client *Client) DoAction(request Request, response Response, opts ...RequestOption) (err error) {
 	for _, opt := range opts {
 		opt(request)
@@ -277,92 +374,94 @@ func (client *Client) DoAction(request Request, response Response, opts ...Reque
 	fieldMap := make(map[string]string)
 	initLogMsg(fieldMap)
 	defer func() {
-		client.printLog(fieldMap, err)
+		level := LogLevelInfo
+		if err != nil {
+			level = LogLevelError
+		}
+		client.printLog(request, level, fieldMap, err)
 	}()
 
-	httpRequest, err := client.buildRequest(request)
-	if err != nil {
-		return
-	}
+	eventType, account := getEventInfo(request)
+	actionStart := time.Now()
+	retries := 0
+	defer func() {
+		client.metrics.ObserveOutboundEvent(eventType, outboundStatus(response, err), retries, time.Since(actionStart))
+	}()
 
-	client.setTimeout(request)
-	proxy, err := client.getHttpProxy(httpRequest.URL.Scheme)
-	if err != nil {
-		return err
-	}
-	noProxy := client.getNoProxy(httpRequest.URL.Scheme)
+	ctx, span := client.startSpan(requestContext(request), "uim.DoAction", trace.SpanKindClient,
+		attribute.String("cloudevents.event_type", eventType),
+	)
+	defer func() {
+		endSpan(span, err)
+	}()
 
-	var withoutProxy bool
-	for _, value := range noProxy {
-		if strings.HasPrefix(value, "*") {
-			value = fmt.Sprintf(".%s", value)
-		}
-		noProxyReg, err := regexp.Compile(value)
-		if err != nil {
-			return err
-		}
-		if noProxyReg.MatchString(httpRequest.Host) {
-			withoutProxy = true
-			break
-		}
+	httpRequest, err := client.buildRequest(ctx, request)
+	if err != nil {
+		return
 	}
+	span.SetAttributes(
+		attribute.String("http.method", httpRequest.Method),
+		attribute.String("http.url", httpRequest.URL.String()),
+	)
 
-	// Set whether to ignore certificate validation.
-	// Default InsecureSkipVerify is false.
-	if trans, ok := client.httpClient.Transport.(*http.Transport); ok && trans != nil {
-		if trans.TLSClientConfig != nil {
-			trans.TLSClientConfig.InsecureSkipVerify = client.getHTTPSInsecure(request)
-		} else {
-			trans.TLSClientConfig = &tls.Config{
-				InsecureSkipVerify: client.getHTTPSInsecure(request),
-			}
-		}
-		if proxy != nil && !withoutProxy {
-			trans.Proxy = http.ProxyURL(proxy)
-		}
-		client.httpClient.Transport = trans
-	}
+	httpClient := client.getHttpClient(request)
+	cancel := context.CancelFunc(func() {})
+	defer func() {
+		cancel()
+	}()
 
 	var httpResponse *http.Response
 	for retryTimes := 0; retryTimes <= int(client.options.MaxRetryTime); retryTimes++ {
 		if retryTimes > 0 {
-			client.printLog(fieldMap, err)
+			// 重试前记录上一次失败的调用
+			client.printLog(request, LogLevelWarn, fieldMap, err)
 			initLogMsg(fieldMap)
 		}
 		putMsgToMap(fieldMap, httpRequest)
 
-		debug("> %s %s %s", httpRequest.Method, httpRequest.URL.RequestURI(), httpRequest.Proto)
-		debug("> Host: %s", httpRequest.Host)
-		for key, value := range httpRequest.Header {
-			debug("> %s: %v", key, strings.Join(value, ""))
+		retries = retryTimes
+		// 熔断时直接返回，不占用限流的令牌
+		if client.breaker != nil && !client.breaker.allow() {
+			err = NewClientError(CircuitOpenErrorCode, CircuitOpenErrorMessage, nil)
+			return
 		}
-		debug(">")
-		debug(" Retry Times: %d.", retryTimes)
+		if err = client.waitRateLimit(ctx, account); err != nil {
+			if client.breaker != nil {
+				client.breaker.release()
+			}
+			return
+		}
+
+		cancel()
+		httpRequest, cancel = client.withTimeout(ctx, request, httpRequest)
 
+		client.dumpRequest(httpRequest, request.GetContent(), retryTimes)
 		startTime := time.Now()
 		fieldMap["{start_time}"] = startTime.Format("2006-01-02 15:04:05")
-		httpResponse, err = client.httpClient.Do(httpRequest)
-		fieldMap["{cost}"] = time.Since(startTime).String()
+		httpResponse, err = httpClient.Do(httpRequest)
+		cost := time.Since(startTime)
+		fieldMap["{cost}"] = cost.String()
+		client.logRequest(ctx, eventType, retryTimes, request, httpRequest, httpResponse, cost, err)
 
+		if client.breaker != nil {
+			client.breaker.record(err == nil && !isServerError(httpResponse))
+		}
+
+		span.SetAttributes(attribute.Int("http.retry_count", retryTimes))
 		if err == nil {
+			span.SetAttributes(attribute.Int("http.status_code", httpResponse.StatusCode))
 			fieldMap["{code}"] = strconv.Itoa(httpResponse.StatusCode)
 			fieldMap["{res_headers}"] = toString(httpResponse.Header)
-
-			debug("< %s %s", httpResponse.Proto, httpResponse.Status)
-			for key, value := range httpResponse.Header {
-				debug("< %s: %v", key, strings.Join(value, ""))
-			}
 		}
-		debug("<")
 
 		// receive error
 		if err != nil {
-			debug(" Error: %s.", err.Error())
+			client.dumpError(err)
 			if !client.options.AutoRetry {
 				return
 			} else if retryTimes >= int(client.options.MaxRetryTime) {
 				// timeout but reached the max retry times, return
-				if strings.Contains(err.Error(), "Client.Timeout") {
+				if isTimeoutError(err) {
 					times := strconv.Itoa(retryTimes + 1)
 					timeoutErrorMsg := fmt.Sprintf(TimeoutErrorMessage, times, times)
 					err = NewClientError(TimeoutErrorCode, timeoutErrorMsg, err)
@@ -378,10 +477,15 @@ func (client *Client) DoAction(request Request, response Response, opts ...Reque
 		}
 
 		//  if status code >= 500 or timeout, will trigger retry
-		if client.options.AutoRetry && (err != nil || isServerError(httpResponse)) {
-			client.setTimeout(request)
+		// 最后一次重试的返回需要读取 body 解析错误，不能关闭
+		if client.options.AutoRetry && retryTimes < int(client.options.MaxRetryTime) && (err != nil || isServerError(httpResponse)) {
+			if httpResponse != nil {
+				client.dumpResponse(httpResponse, nil)
+				_, _ = io.Copy(io.Discard, httpResponse.Body)
+				httpResponse.Body.Close()
+			}
 			// rewrite signatureNonce and signature
-			httpRequest, err = client.buildRequest(request)
+			httpRequest, err = client.buildRequest(ctx, request)
 			// buildHttpRequest(request, finalSigner, regionId)
 			if err != nil {
 				return
@@ -392,32 +496,45 @@ func (client *Client) DoAction(request Request, response Response, opts ...Reque
 	}
 
 	err = unmarshalResponse(response, httpResponse, request.GetAcceptFormat())
-	fieldMap["{res_body}"] = response.GetHttpContentString()
-	debug("%s", response.GetHttpContentString())
+	fieldMap["{res_body}"] = string(redactJSON(response.GetHttpContentBytes()))
+	client.dumpResponse(httpResponse, response.GetHttpContentBytes())
 	return
 }
 
 func (client *Client) Authorize() (accessToken string, expiresIn int64, err error) {
+	return client.authorize(context.Background())
+}
+
+func (client *Client) authorize(ctx context.Context) (accessToken string, expiresIn int64, err error) {
+	ctx, span := client.startSpan(ctx, "uim.Authorize", trace.SpanKindClient,
+		attribute.String("http.url", client.options.TokenEndpoint),
+	)
+	defer func() {
+		endSpan(span, err)
+	}()
+
 	payload, _ := json.Marshal(map[string]string{
 		"client_id":     client.options.ClientId,
 		"client_secret": client.options.ClientSecret,
 		"audience":      client.options.ClientAudience,
 		"grant_type":    "client_credentials",
 	})
-	req, _ := http.NewRequest("POST", client.options.TokenEndpoint, bytes.NewReader(payload))
+	req, _ := http.NewRequestWithContext(ctx, "POST", client.options.TokenEndpoint, bytes.NewReader(payload))
 	req.Header.Add("content-type", "application/json")
-	res, err := http.DefaultClient.Do(req)
+	client.dumpRequest(req, redactJSON(payload), 0)
+	// 和调用 UIM 使用相同的 Transport，WithTransport 录制回放时也包括获取 token 的请求
+	res, err := client.httpClient.Do(req)
 	if err != nil {
-		debug("%v", err)
+		client.dumpError(err)
 		return "", 0, err
 	}
 	defer res.Body.Close()
 	body, _ := ioutil.ReadAll(res.Body)
+	client.dumpResponse(res, redactJSON(body))
 
 	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
 		result := make(map[string]string)
 		if err = json.Unmarshal(body, &result); err != nil {
-			debug("%s", string(body))
 			return "", 0, err
 		}
 		return "", 0, NewClientError(AuthenticationFailedErrorCode, AuthenticationFailedErrorMessage, nil)
@@ -425,7 +542,6 @@ func (client *Client) Authorize() (accessToken string, expiresIn int64, err erro
 
 	result := make(map[string]any)
 	if err = json.Unmarshal(body, &result); err != nil {
-		debug("%s", string(body))
 		return "", 0, err
 	}
 	accessToken = result["access_token"].(string)
@@ -453,36 +569,128 @@ func (client *Client) ValidateToken(token string) (any, error) {
 	return jwtValidator.ValidateToken(context.TODO(), token)
 }
 
-func (client *Client) newEvent(eventType string, data any) *cloudevents.Event {
+func (client *Client) newEvent(ctx context.Context, eventType string, data any) *cloudevents.Event {
 	id, _ := gonanoid.New()
 	ce := cloudevents.NewEvent()
 	ce.SetID(id)
 	ce.SetSource(client.options.EventSource)
 	ce.SetType(eventType)
 	ce.SetData(cloudevents.ApplicationJSON, data)
+	if ctx != nil {
+		tracePropagator.Inject(ctx, eventCarrier{&ce})
+	}
 	return &ce
 }
 
-func (client *Client) SendEvent(eventType string, data any, opts ...RequestOption) (err error) {
-	event := client.newEvent(eventType, data)
+func (client *Client) SendEvent(eventType string, data any, opts ...RequestOption) error {
+	key, send, err := client.prepareEvent(eventType, data, opts)
+	if err != nil {
+		return err
+	}
+	if key != "" {
+		return client.ordering.Do(key, send)
+	}
+	return send()
+}
+
+// 异步发送事件，发送完成后调用 callback，callback 可以为 nil。
+// 开启有序发送时，事件在调用时按会话排队，相同会话的事件按调用顺序发送，由会话的 goroutine 发送，不占用异步任务池；
+// 通过 AddAsyncTask 调用 SendEvent 时，发送顺序取决于任务的调度顺序
+func (client *Client) SendEventAsync(eventType string, data any, callback func(error), opts ...RequestOption) error {
+	if client.asyncTaskQueue == nil {
+		return NewClientError(AsyncFunctionNotEnabledCode, AsyncFunctionNotEnabledMessage, nil)
+	}
+	key, send, err := client.prepareEvent(eventType, data, opts)
+	if err != nil {
+		return err
+	}
+	task := func() {
+		err := send()
+		if callback != nil {
+			callback(err)
+		}
+	}
+	if client.outbox != nil {
+		// 发件箱由后台按会话顺序投递，在调用时写入以保留调用顺序
+		task()
+		return nil
+	}
+	if key != "" {
+		client.ordering.Submit(key, task)
+		return nil
+	}
+	return client.AddAsyncTask(task)
+}
+
+// 检查事件数据并生成请求，返回有序发送的 key 和发送函数，未开启有序发送或写入发件箱时 key 为空
+func (client *Client) prepareEvent(eventType string, data any, opts []RequestOption) (key string, send func() error, err error) {
+	req := NewBaseRequest()
+	for _, opt := range opts {
+		opt(req)
+	}
+	ctx, span := client.startSpan(req.GetContext(), eventType+" send", trace.SpanKindProducer)
+	req.SetContext(ctx)
+
+	if err = checkEventData(eventType, data); err == nil {
+		if err = validateData(data); err != nil {
+			err = newInvalidEventDataClientError(err)
+		}
+	}
+	if err != nil {
+		endSpan(span, err)
+		return "", nil, err
+	}
+	event := client.newEvent(ctx, eventType, data)
+	span.SetAttributes(eventAttributes(event)...)
 	content := new(bytes.Buffer)
 	enc := json.NewEncoder(content)
 	enc.SetEscapeHTML(false)
 	_ = enc.Encode(event)
-	req := NewBaseRequest()
+	if client.outbox != nil {
+		outboxKey := getStringField(data, "Channel", "Account")
+		return "", func() error {
+			err := client.outbox.enqueue(event.ID(), outboxKey, eventType, content.Bytes(), opts)
+			endSpan(span, err)
+			return err
+		}, nil
+	}
 	req.SetContent(content.Bytes())
-	return client.DoAction(req, &BaseResponse{}, opts...)
+	if client.ordering != nil {
+		key = client.orderingKey(data)
+	}
+	return key, func() error {
+		err := client.DoAction(req, &BaseResponse{})
+		endSpan(span, err)
+		return err
+	}, nil
 }
 
 func (client *Client) Invoke(commandType string, data any, resp Response, opts ...RequestOption) (Response, error) {
-	command := client.newEvent(commandType, data)
+	req := NewBaseRequest()
+	for _, opt := range opts {
+		opt(req)
+	}
+	ctx, span := client.startSpan(req.GetContext(), commandType+" invoke", trace.SpanKindClient)
+	req.SetContext(ctx)
+
+	if err := checkCommandData(commandType, data, resp); err != nil {
+		endSpan(span, err)
+		return resp, err
+	}
+	if err := validateData(data); err != nil {
+		err = newInvalidEventDataClientError(err)
+		endSpan(span, err)
+		return resp, err
+	}
+	command := client.newEvent(ctx, commandType, data)
+	span.SetAttributes(eventAttributes(command)...)
 	content := new(bytes.Buffer)
 	enc := json.NewEncoder(content)
 	enc.SetEscapeHTML(false)
 	_ = enc.Encode(command)
-	req := NewBaseRequest()
 	req.SetContent(content.Bytes())
-	err := client.DoAction(req, resp, opts...)
+	err := client.DoAction(req, resp)
+	endSpan(span, err)
 	return resp, err
 }
 
@@ -492,28 +700,68 @@ func (c *Client) OnEvent(event string, handler EventHandler) {
 	c.eventHandlers[event] = handler
 }
 
+// 已注册处理函数的事件和指令类型，按类型排序
+func (c *Client) HandledEventTypes() []string {
+	c.eventLock.RLock()
+	defer c.eventLock.RUnlock()
+	return c.handledEventTypes()
+}
+
+// 调用方需要持有 eventLock
+func (c *Client) handledEventTypes() []string {
+	types := make([]string, 0, len(c.eventHandlers))
+	for eventType := range c.eventHandlers {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// 验证收到的事件请求中的 Bearer token
+func (c *Client) validateEventToken(r *http.Request) error {
+	token := r.Header.Get("Authorization")
+	if token == "" || !strings.HasPrefix(token, "Bearer ") {
+		return NewServerError(
+			UnauthorizedErrorStatus,
+			UnauthorizedErrorCode,
+			UnauthorizedErrorMessage,
+			nil,
+		)
+	}
+	token = strings.Split(token, " ")[1]
+	if _, err := c.ValidateToken(token); err != nil {
+		return NewServerError(
+			UnauthorizedErrorStatus,
+			UnauthorizedErrorCode,
+			UnauthorizedErrorMessage,
+			err,
+		)
+	}
+	return nil
+}
+
 func (c *Client) EventHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		token := r.Header.Get("Authorization")
-		if token == "" || !strings.HasPrefix(token, "Bearer ") {
-			writeError(w, NewServerError(
-				UnauthorizedErrorStatus,
-				UnauthorizedErrorCode,
-				UnauthorizedErrorMessage,
-				nil,
-			))
-			return
+		if c.dumper != nil {
+			body, _ := ioutil.ReadAll(r.Body)
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			c.dumper.dumpRequest("<", r, body, "")
+			dw := &dumpResponseWriter{ResponseWriter: w}
+			defer func() {
+				if dw.status == 0 {
+					dw.status = http.StatusOK
+				}
+				c.dumper.dumpResponse(">", r.Proto, dw.status, dw.Header(), dw.body.Bytes())
+			}()
+			w = dw
 		}
-		token = strings.Split(token, " ")[1]
-		_, err := c.ValidateToken(token)
-		if err != nil {
-			writeError(w, NewServerError(
-				UnauthorizedErrorStatus,
-				UnauthorizedErrorCode,
-				UnauthorizedErrorMessage,
-				err,
-			))
-			return
+
+		if c.options.EventAuthorization {
+			if err := c.validateEventToken(r); err != nil {
+				c.logEvent(r.Context(), r, nil, "", UnauthorizedErrorCode, 0)
+				writeError(w, err)
+				return
+			}
 		}
 
 		c.eventLock.RLock()
@@ -521,8 +769,9 @@ func (c *Client) EventHandler() http.HandlerFunc {
 
 		body, _ := ioutil.ReadAll(r.Body)
 		event := cloudevents.NewEvent()
-		err = json.Unmarshal(body, &event)
+		err := json.Unmarshal(body, &event)
 		if err != nil {
+			c.logEvent(r.Context(), r, body, "", InvalidEventFormatErrorCode, 0)
 			writeError(w, NewServerError(
 				InvalidEventFormatErrorStatus,
 				InvalidEventFormatErrorCode,
@@ -532,8 +781,21 @@ func (c *Client) EventHandler() http.HandlerFunc {
 			return
 		}
 
-		if handler, ok := c.eventHandlers[event.Type()]; ok {
-			if resp, err := handler(&event); err == nil {
+		if handler, ok := c.lookupEventHandler(event.Type()); ok {
+			// 处理函数可以通过 EventContext 获取当前 span，继续传递 trace context
+			ctx := tracePropagator.Extract(r.Context(), eventCarrier{&event})
+			ctx, span := c.startSpan(ctx, event.Type()+" process", trace.SpanKindConsumer, eventAttributes(&event)...)
+			tracePropagator.Inject(ctx, eventCarrier{&event})
+			start := time.Now()
+			resp, err := handler(&event)
+			if err == nil {
+				err = checkCommandResponse(event.Type(), resp)
+			}
+			endSpan(span, err)
+			cost := time.Since(start)
+			c.metrics.ObserveInboundEvent(event.Type(), inboundResult(err), cost)
+			c.logEvent(ctx, r, body, event.Type(), inboundResult(err), cost)
+			if err == nil {
 				if resp == nil {
 					w.WriteHeader(http.StatusOK)
 					return
@@ -565,6 +827,8 @@ func (c *Client) EventHandler() http.HandlerFunc {
 			}
 
 		} else {
+			c.metrics.ObserveInboundEvent(event.Type(), UnsupportedEventTypeErrorCode, 0)
+			c.logEvent(r.Context(), r, body, event.Type(), UnsupportedEventTypeErrorCode, 0)
 			writeError(w, NewServerError(
 				UnsupportedEventTypeErrorStatus,
 				UnsupportedEventTypeErrorCode,
@@ -614,6 +878,14 @@ func marshalBody(request Request) (err error) {
 	return
 }
 
+func isTimeoutError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
+
 func isCertificateError(err error) bool {
 	return err != nil && strings.Contains(err.Error(), "x509: certificate signed by unknown authority")
 }
@@ -626,7 +898,7 @@ func putMsgToMap(fieldMap map[string]string, request *http.Request) {
 	fieldMap["{version}"] = strings.Split(request.Proto, "/")[1]
 	hostname, _ := os.Hostname()
 	fieldMap["{hostname}"] = hostname
-	fieldMap["{req_headers}"] = toString(request.Header)
+	fieldMap["{req_headers}"] = toString(redactHeaders(request.Header))
 	fieldMap["{target}"] = request.URL.Path + request.URL.RawQuery
 }
 
@@ -644,20 +916,54 @@ func NewClient(opts ...Option) (client *Client) {
 	}
 	options := client.options
 
-	client.httpClient = &http.Client{}
-	if options.Transport != nil {
-		client.httpClient.Transport = options.Transport
-	} else if options.HttpTransport != nil {
-		client.httpClient.Transport = options.HttpTransport
+	if options.LogHandler != nil {
+		client.slog = slog.New(options.LogHandler)
 	}
-	if options.ReadTimeout > 0 {
-		client.httpClient.Timeout = options.ReadTimeout
+	// 兼容环境变量 DEBUG=sdk
+	if options.Debug || isDebugEnabled("sdk") {
+		client.dumper = newWireDumper(options.DebugOutput, int(options.DebugBodyLimit))
 	}
 
+	client.metrics = noopMetrics{}
+	if options.Metrics != nil {
+		client.metrics = options.Metrics
+		client.metrics.SetCircuitState(CircuitStateClosed)
+	}
+
+	client.httpClient = client.newHttpClient(false)
+	client.insecureHttpClient = client.newHttpClient(true)
+
 	if options.EnableAsync {
 		client.enableAsync(options.GoRoutinePoolSize, options.MaxTaskQueueSize)
 	}
 
+	if options.RateLimit > 0 {
+		client.rateLimiter = NewRateLimiter(options.RateLimit, options.RateBurst)
+	}
+	if options.AccountRateLimit > 0 {
+		client.accountRateLimiter = NewRateLimiter(options.AccountRateLimit, options.AccountRateBurst)
+	}
+
+	if options.CircuitBreaker != nil {
+		settings := *options.CircuitBreaker
+		onStateChange := settings.OnStateChange
+		settings.OnStateChange = func(from, to CircuitState) {
+			client.metrics.SetCircuitState(to)
+			if onStateChange != nil {
+				onStateChange(from, to)
+			}
+		}
+		client.breaker = newCircuitBreaker(settings)
+	}
+
+	if options.OrderedDelivery {
+		client.ordering = newOrderedExecutor()
+	}
+
+	if options.Outbox != nil {
+		client.outbox = newOutboxDispatcher(client, options.Outbox)
+	}
+
 	return client
 }
 
@@ -669,7 +975,10 @@ func CastEventHandler[D any](handler func(*cloudevents.Event, *D) error) EventHa
 	return func(event *cloudevents.Event) (any, error) {
 		data := new(D)
 		if err := event.DataAs(data); err != nil {
-			return nil, err
+			return nil, newInvalidEventDataServerError(err)
+		}
+		if err := validateData(data); err != nil {
+			return nil, newInvalidEventDataServerError(err)
 		}
 		err := handler(event, data)
 		return nil, err
@@ -680,7 +989,10 @@ func CastCommandHandler[D any, R any](handler func(*cloudevents.Event, *D) (*R,
 	return func(event *cloudevents.Event) (any, error) {
 		data := new(D)
 		if err := event.DataAs(data); err != nil {
-			return nil, err
+			return nil, newInvalidEventDataServerError(err)
+		}
+		if err := validateData(data); err != nil {
+			return nil, newInvalidEventDataServerError(err)
 		}
 		return handler(event, data)
 	}
@@ -0,0 +1,151 @@
+package uim
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 需要使用 -race 运行：go test -race -run TestConcurrentDoAction
+func TestConcurrentDoAction(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if delay, err := time.ParseDuration(r.URL.Query().Get("delay")); err == nil {
+			time.Sleep(delay)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	// 忽略证书验证失败时的握手错误日志
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	client := NewClient(
+		WithAuthorization(false),
+		WithBaseUrl(server.URL),
+		WithTimeout(5*time.Second, time.Second),
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := NewBaseRequest()
+			switch i % 3 {
+			case 0:
+				// 超时很短的慢请求
+				req.AddQueryParam("delay", "200ms")
+				err := client.DoAction(req, &BaseResponse{},
+					WithRequestHTTPSInsecure(true),
+					WithRequestTimeout(20*time.Millisecond, 0),
+				)
+				assert.True(t, isTimeoutError(err), "%v", err)
+			case 1:
+				// 使用客户端默认超时的慢请求，不受其他请求超时设置的影响
+				req.AddQueryParam("delay", "50ms")
+				err := client.DoAction(req, &BaseResponse{}, WithRequestHTTPSInsecure(true))
+				assert.Nil(t, err)
+			case 2:
+				// 不跳过证书验证的请求，不受其他请求证书设置的影响
+				err := client.DoAction(req, &BaseResponse{})
+				assert.True(t, isCertificateError(err), "%v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestConcurrentDoActionWithRetry(t *testing.T) {
+	var lock sync.Mutex
+	attempts := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		attempts[r.URL.Query().Get("id")]++
+		attempt := attempts[r.URL.Query().Get("id")]
+		lock.Unlock()
+		if attempt == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAuthorization(false),
+		WithBaseUrl(server.URL),
+		WithAutoRetry(true, 2),
+		WithAsync(true, 100, 5),
+	)
+	defer client.Shutdown()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		id := string(rune('a' + i))
+		err := client.AddAsyncTask(func() {
+			defer wg.Done()
+			req := NewBaseRequest()
+			req.AddQueryParam("id", id)
+			err := client.DoAction(req, &BaseResponse{}, WithRequestTimeout(time.Second, time.Second))
+			assert.Nil(t, err)
+		})
+		assert.Nil(t, err)
+	}
+	wg.Wait()
+}
+
+func TestDoActionRetryExhausted(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"code":"InternalError","message":"boom"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAuthorization(false),
+		WithBaseUrl(server.URL),
+		WithAutoRetry(true, 2),
+	)
+	err := client.DoAction(NewBaseRequest(), &BaseResponse{})
+	assert.Equal(t, 3, attempts)
+	serverError, ok := err.(*ServerError)
+	assert.True(t, ok, "%v", err)
+	assert.Equal(t, http.StatusInternalServerError, serverError.HttpStatus())
+	assert.Equal(t, "InternalError", serverError.ErrorCode())
+}
+
+// 只实现了 Request 接口的请求，没有 context
+type noContextRequest struct {
+	Request
+}
+
+func TestDoActionRequestContext(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAuthorization(false), WithBaseUrl(server.URL))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := client.DoAction(NewBaseRequest(), &BaseResponse{}, WithRequestContext(ctx))
+	assert.True(t, errors.Is(err, context.Canceled), "%v", err)
+	assert.Equal(t, 0, attempts)
+
+	// 不支持 context 的请求忽略 WithRequestContext
+	err = client.DoAction(&noContextRequest{NewBaseRequest()}, &BaseResponse{}, WithRequestContext(ctx))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, attempts)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	uim "github.com/uimkit/provider-go"
+	"gopkg.in/yaml.v3"
+)
+
+// 注册客户端配置参数，解析后调用返回的函数读取配置
+// 配置依次从配置文件、UIM_* 环境变量和命令行参数读取，后者覆盖前者，见 uim.LoadOptions
+func configFlags(fs *flag.FlagSet, stderr io.Writer) func() (*uim.Options, error) {
+	configFile := fs.String("config", os.Getenv("UIM_CONFIG"), "config file in yaml, json or toml, env UIM_CONFIG")
+	clientId := fs.String("client-id", "", "client id used to fetch access token, env UIM_CLIENT_ID")
+	clientSecret := fs.String("client-secret", "", "client secret used to fetch access token, env UIM_CLIENT_SECRET")
+	audience := fs.String("audience", "", "audience of the access token, env UIM_CLIENT_AUDIENCE")
+	issuer := fs.String("issuer", "", "issuer of tokens sent by UIM, used to validate incoming events, env UIM_SERVER_ISSUER")
+	serverAudience := fs.String("server-audience", "", "audience of tokens sent by UIM, env UIM_SERVER_AUDIENCE")
+	tokenEndpoint := fs.String("token-endpoint", "", "endpoint to fetch access token, env UIM_TOKEN_ENDPOINT")
+	baseUrl := fs.String("base-url", "", "base url of UIM or provider api, env UIM_BASE_URL")
+	eventSource := fs.String("event-source", "", "cloudevents source, e.g. provider.source/<provider>/<strategy>, env UIM_EVENT_SOURCE")
+	auth := fs.Bool("auth", true, "fetch access token and authorize requests, env UIM_ENABLE_AUTHORIZATION")
+	insecure := fs.Bool("insecure", false, "skip tls certificate verification, env UIM_IS_INSECURE")
+	timeout := fs.Duration("timeout", 0, "read timeout of requests, e.g. 30s, env UIM_READ_TIMEOUT")
+	debug := fs.Bool("debug", false, "dump requests and responses to stderr, env UIM_DEBUG")
+
+	return func() (*uim.Options, error) {
+		var opts []uim.Option
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "client-id":
+				opts = append(opts, func(o *uim.Options) { o.ClientId = *clientId })
+			case "client-secret":
+				opts = append(opts, func(o *uim.Options) { o.ClientSecret = *clientSecret })
+			case "audience":
+				opts = append(opts, func(o *uim.Options) { o.ClientAudience = *audience })
+			case "issuer":
+				opts = append(opts, func(o *uim.Options) { o.ServerIssuer = *issuer })
+			case "server-audience":
+				opts = append(opts, func(o *uim.Options) { o.ServerAudience = *serverAudience })
+			case "token-endpoint":
+				opts = append(opts, uim.WithTokenEndpoint(*tokenEndpoint))
+			case "base-url":
+				opts = append(opts, uim.WithBaseUrl(*baseUrl))
+			case "event-source":
+				opts = append(opts, uim.WithEventSource(*eventSource))
+			case "auth":
+				opts = append(opts, uim.WithAuthorization(*auth))
+			case "insecure":
+				opts = append(opts, uim.WithSSL(*insecure))
+			case "timeout":
+				opts = append(opts, func(o *uim.Options) { o.ReadTimeout = *timeout })
+			case "debug":
+				opts = append(opts, uim.WithDebug(*debug))
+			}
+		})
+		// 调试输出到标准错误，不影响标准输出的结果
+		opts = append(opts, func(o *uim.Options) {
+			if o.DebugOutput == nil {
+				o.DebugOutput = stderr
+			}
+		})
+		return uim.LoadOptions(*configFile, opts...)
+	}
+}
+
+func isYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+func readContent(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// 读取 JSON 或 YAML 文件，根据扩展名判断格式
+func readFile(path string, v any) error {
+	content, err := readContent(path)
+	if err != nil {
+		return err
+	}
+	if isYAML(path) {
+		err = yaml.Unmarshal(content, v)
+	} else {
+		err = json.Unmarshal(content, v)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+// 读取事件数据，YAML 会被转换为 JSON，path 为 - 时读取标准输入
+func readPayload(path string) ([]byte, error) {
+	if !isYAML(path) {
+		return readContent(path)
+	}
+	var value any
+	if err := readFile(path, &value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
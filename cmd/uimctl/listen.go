@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	uim "github.com/uimkit/provider-go"
+	"github.com/uimkit/provider-go/uimtest"
+	"github.com/uimkit/provider-go/uimtest/issuer"
+)
+
+// 收到事件时的验证方式
+const (
+	authModeJWKS = "jwks" // 使用 issuer 的 JWKS 验证
+	authModeFake = "fake" // 启动本地的模拟认证服务，打印可用的 token
+	authModeNone = "none" // 不验证
+)
+
+// 本地接收事件的服务
+type listener struct {
+	stdout    *lockedWriter
+	client    *uim.Client
+	responses string // 指令返回数据的目录，文件名为 <command-type>.json 或 <command-type>.yaml
+	proxy     string // 转发指令的地址
+}
+
+type listenFlags struct {
+	addr      string
+	path      string
+	authMode  string
+	responses string
+	proxy     string
+	record    string
+}
+
+func (c *ctl) listen(args []string) error {
+	fs := flag.NewFlagSet("listen", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: uimctl listen [flags]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	flags := &listenFlags{}
+	fs.StringVar(&flags.addr, "addr", ":8910", "address to listen on")
+	fs.StringVar(&flags.path, "path", "/", "path of the event webhook")
+	fs.StringVar(&flags.authMode, "auth-mode", authModeJWKS, "how to validate incoming tokens: jwks, fake or none")
+	fs.StringVar(&flags.responses, "responses", "", "directory of canned command responses named <command-type>.json or .yaml")
+	fs.StringVar(&flags.proxy, "proxy", "", "forward commands to this url and answer with its response")
+	fs.StringVar(&flags.record, "record", "", "record the session to this file, replayable with uimtest.RecordingTransport")
+	load := configFlags(fs, c.stderr)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return errors.New("listen takes no arguments")
+	}
+	options, err := load()
+	if err != nil {
+		return err
+	}
+
+	opts := []uim.Option{uim.WithOptions(options)}
+	switch flags.authMode {
+	case authModeJWKS:
+	case authModeFake:
+		fake := issuer.New(options.ServerAudience)
+		defer fake.Close()
+		opts = append(opts, fake.ServerOption())
+		fmt.Fprintf(c.stderr, "fake issuer %s\ntoken: %s\n", fake.URL(), fake.Token())
+	case authModeNone:
+		opts = append(opts, uim.WithEventAuthorization(false))
+	default:
+		return fmt.Errorf("unsupported auth mode %q", flags.authMode)
+	}
+
+	l := &listener{
+		stdout:    &lockedWriter{w: c.stdout},
+		client:    uim.NewClient(opts...),
+		responses: flags.responses,
+		proxy:     flags.proxy,
+	}
+	var handler http.Handler = l.handler()
+	var recorder *uimtest.RecordingHandler
+	if flags.record != "" {
+		recorder = uimtest.NewRecordingHandler(handler)
+		handler = recorder
+	}
+	mux := http.NewServeMux()
+	mux.Handle(flags.path, handler)
+	server := &http.Server{Addr: flags.addr, Handler: mux}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+	fmt.Fprintf(c.stderr, "listening on %s%s\n", flags.addr, flags.path)
+
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err = server.Shutdown(shutdownCtx)
+		cancel()
+	}
+	if recorder != nil {
+		if saveErr := recorder.Save(flags.record); saveErr != nil {
+			return saveErr
+		}
+		fmt.Fprintf(c.stderr, "recorded %d events to %s\n", len(recorder.Interactions()), flags.record)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// 注册所有已知的事件和指令，打印收到的事件，指令使用预设的返回数据或转发，自动回复的指令不注册
+func (l *listener) handler() http.HandlerFunc {
+	for _, info := range uim.EventTypes() {
+		if uim.IsBuiltinCommand(info.Type) {
+			continue
+		}
+		if info.IsCommand() {
+			l.client.OnEvent(info.Type, l.handleCommand)
+		} else {
+			l.client.OnEvent(info.Type, l.handleEvent)
+		}
+	}
+	return l.client.EventHandler()
+}
+
+func (l *listener) print(event *cloudevents.Event) {
+	data := event.Data()
+	if formatted, err := prettyFormat(data, isTerminal(l.stdout.w)); err == nil {
+		data = formatted
+	}
+	l.stdout.printf("%s %s id=%s source=%s\n%s\n", time.Now().Format(time.RFC3339), event.Type(), event.ID(), event.Source(), data)
+}
+
+func (l *listener) handleEvent(event *cloudevents.Event) (any, error) {
+	l.print(event)
+	return nil, nil
+}
+
+func (l *listener) handleCommand(event *cloudevents.Event) (any, error) {
+	l.print(event)
+	if l.proxy != "" {
+		return l.forward(event)
+	}
+	if l.responses != "" {
+		for _, ext := range []string{".json", ".yaml", ".yml"} {
+			path := filepath.Join(l.responses, event.Type()+ext)
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			content, err := readPayload(path)
+			if err != nil {
+				return nil, err
+			}
+			return json.RawMessage(content), nil
+		}
+	}
+	return nil, nil
+}
+
+// 转发原始事件，返回转发地址的响应
+func (l *listener) forward(event *cloudevents.Event) (any, error) {
+	content, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	req := uim.NewBaseRequest()
+	req.SetContent(content)
+	resp := &uim.BaseResponse{}
+	if err := l.client.DoAction(req, resp, uim.WithRequestBaseUrl(l.proxy)); err != nil {
+		return nil, err
+	}
+	if len(resp.GetHttpContentBytes()) == 0 {
+		return nil, nil
+	}
+	return json.RawMessage(resp.GetHttpContentBytes()), nil
+}
+
+// 并发处理事件时串行输出
+type lockedWriter struct {
+	lock sync.Mutex
+	w    io.Writer
+}
+
+func (w *lockedWriter) printf(format string, args ...any) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	fmt.Fprintf(w.w, format, args...)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	uim "github.com/uimkit/provider-go"
+	"github.com/uimkit/provider-go/server"
+	"github.com/uimkit/provider-go/uimtest"
+)
+
+func newServerClient(baseUrl string, transport http.RoundTripper) *server.Client {
+	opts := []uim.Option{
+		uim.WithAuthorization(false),
+		server.WithServerName("test"),
+		uim.WithBaseUrl(baseUrl),
+	}
+	if transport != nil {
+		opts = append(opts, uim.WithTransport(transport))
+	}
+	return server.NewClient(opts...)
+}
+
+func TestListen(t *testing.T) {
+	responses := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(responses, uim.UIMCommandGetChannelInfo+".yaml"), []byte("user:\n  user_id: u1\n"), 0644))
+
+	stdout := new(bytes.Buffer)
+	l := &listener{
+		stdout:    &lockedWriter{w: stdout},
+		client:    uim.NewClient(uim.WithEventAuthorization(false)),
+		responses: responses,
+	}
+	recorder := uimtest.NewRecordingHandler(l.handler())
+	ts := httptest.NewServer(recorder)
+	defer ts.Close()
+
+	client := newServerClient(ts.URL, nil)
+	resp, err := client.GetChannelInfo(&uim.GetChannelInfoRequest{Channel: "c1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "u1", resp.User.UserId)
+	assert.True(t, strings.Contains(stdout.String(), uim.UIMCommandGetChannelInfo), stdout.String())
+	assert.True(t, strings.Contains(stdout.String(), `"channel": "c1"`), stdout.String())
+
+	// 录制的会话可以用 RecordingTransport 回放
+	session := filepath.Join(t.TempDir(), "session.json")
+	assert.Nil(t, recorder.Save(session))
+	ts.Close()
+	replayer, err := uimtest.NewRecordingTransport(session, uimtest.ModeReplay, nil)
+	assert.Nil(t, err)
+	resp, err = newServerClient(ts.URL, replayer).GetChannelInfo(&uim.GetChannelInfoRequest{Channel: "c1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "u1", resp.User.UserId)
+}
+
+func TestListenProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code":"SDK.InvalidEventData","message":"bad channel"}`))
+	}))
+	defer upstream.Close()
+
+	l := &listener{
+		stdout: &lockedWriter{w: new(bytes.Buffer)},
+		client: uim.NewClient(uim.WithEventAuthorization(false), uim.WithAuthorization(false)),
+		proxy:  upstream.URL,
+	}
+	ts := httptest.NewServer(l.handler())
+	defer ts.Close()
+
+	_, err := newServerClient(ts.URL, nil).GetChannelInfo(&uim.GetChannelInfoRequest{Channel: "c1"})
+	assert.NotNil(t, err)
+	assert.Equal(t, uim.InvalidEventDataErrorCode, err.(*uim.ServerError).ErrorCode())
+}
+
+func TestListenCapabilities(t *testing.T) {
+	l := &listener{
+		stdout: &lockedWriter{w: new(bytes.Buffer)},
+		client: uim.NewClient(uim.WithEventAuthorization(false)),
+	}
+	ts := httptest.NewServer(l.handler())
+	defer ts.Close()
+
+	resp, err := newServerClient(ts.URL, nil).GetCapabilities()
+	assert.Nil(t, err)
+	assert.Contains(t, resp.EventTypes, uim.UIMCommandGetChannelInfo)
+	assert.Contains(t, resp.EventTypes, uim.ProviderEventNewMessage)
+	assert.NotContains(t, resp.EventTypes, uim.UIMCommandGetCapabilities)
+}
+
+func TestListenPing(t *testing.T) {
+	l := &listener{
+		stdout: &lockedWriter{w: new(bytes.Buffer)},
+		client: uim.NewClient(uim.WithEventAuthorization(false), uim.WithEventSource("provider.source/test/test")),
+	}
+	ts := httptest.NewServer(l.handler())
+	defer ts.Close()
+
+	resp, err := newServerClient(ts.URL, nil).Ping()
+	assert.Nil(t, err)
+	assert.Equal(t, uim.Version, resp.Version)
+	assert.Equal(t, "provider.source/test/test", resp.EventSource)
+}
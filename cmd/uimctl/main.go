@@ -0,0 +1,262 @@
+// uimctl 是调试 UIM 集成的命令行工具，可以获取 access token、发送事件、调用指令、校验事件数据和在本地接收事件
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hokaccha/go-prettyjson"
+	uim "github.com/uimkit/provider-go"
+)
+
+const usage = `Usage: uimctl <command> [flags] [args]
+
+Commands:
+  token                     fetch and print an access token
+  send <event-type> <file>  send a provider event with data from a json/yaml file
+  invoke <type> <file>      invoke a command with data from a json/yaml file
+  validate <type> <file>    validate event or command data without sending
+  listen                    receive events and commands from UIM on a local port
+  types                     list supported event and command types
+  schema                    generate JSON Schema and AsyncAPI documents of the events
+
+Use "-" as file to read from stdin. Run "uimctl <command> -h" for flags.
+`
+
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	err := run(os.Args[1:], os.Stdout, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(os.Stderr, usage)
+		} else {
+			fmt.Fprintln(os.Stderr, "uimctl:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+type ctl struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	c := &ctl{stdout: stdout, stderr: stderr}
+	switch args[0] {
+	case "token":
+		return c.token(args[1:])
+	case "send":
+		return c.send(args[1:])
+	case "invoke":
+		return c.invoke(args[1:])
+	case "validate":
+		return c.validate(args[1:])
+	case "listen":
+		return c.listen(args[1:])
+	case "types":
+		return c.types()
+	case "schema":
+		return c.schema(args[1:])
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	default:
+		return errUsage
+	}
+}
+
+// 解析参数，返回客户端配置和位置参数，withConfig 为 false 时不读取客户端配置
+func (c *ctl) parse(name string, args []string, nargs int, argsUsage string, withConfig bool) (*uim.Options, []string, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: uimctl %s [flags] %s\n\nFlags:\n", name, argsUsage)
+		fs.PrintDefaults()
+	}
+	var load func() (*uim.Options, error)
+	if withConfig {
+		load = configFlags(fs, c.stderr)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	if fs.NArg() != nargs {
+		fs.Usage()
+		return nil, nil, fmt.Errorf("%s requires %d arguments", name, nargs)
+	}
+	if load == nil {
+		return nil, fs.Args(), nil
+	}
+	options, err := load()
+	return options, fs.Args(), err
+}
+
+func (c *ctl) token(args []string) error {
+	options, _, err := c.parse("token", args, 0, "", true)
+	if err != nil {
+		return err
+	}
+	client := uim.NewClient(uim.WithOptions(options))
+	accessToken, expiresIn, err := client.Authorize()
+	if err != nil {
+		return err
+	}
+	return c.print(map[string]any{
+		"access_token": accessToken,
+		"expires_in":   expiresIn,
+	})
+}
+
+func (c *ctl) send(args []string) error {
+	options, args, err := c.parse("send", args, 2, "<event-type> <file>", true)
+	if err != nil {
+		return err
+	}
+	info, ok := uim.LookupEventType(args[0])
+	if !ok || info.IsCommand() {
+		return fmt.Errorf("unsupported event type %q, run \"uimctl types\" to list event types", args[0])
+	}
+	data := info.NewData()
+	if err := decodePayload(args[1], data); err != nil {
+		return err
+	}
+	client := uim.NewClient(uim.WithOptions(options))
+	if err := client.SendEvent(args[0], data); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "sent %s\n", args[0])
+	return nil
+}
+
+func (c *ctl) invoke(args []string) error {
+	options, args, err := c.parse("invoke", args, 2, "<command-type> <file>", true)
+	if err != nil {
+		return err
+	}
+	info, ok := uim.LookupEventType(args[0])
+	if !ok || !info.IsCommand() {
+		return fmt.Errorf("unsupported command type %q, run \"uimctl types\" to list command types", args[0])
+	}
+	data := info.NewData()
+	if err := decodePayload(args[1], data); err != nil {
+		return err
+	}
+	client := uim.NewClient(uim.WithOptions(options))
+	resp, err := client.Invoke(args[0], data, info.NewResponse())
+	if err != nil {
+		return err
+	}
+	return c.printJSON(resp.GetHttpContentBytes())
+}
+
+func (c *ctl) validate(args []string) error {
+	_, args, err := c.parse("validate", args, 2, "<type> <file>", false)
+	if err != nil {
+		return err
+	}
+	info, ok := uim.LookupEventType(args[0])
+	if !ok {
+		return fmt.Errorf("unsupported type %q, run \"uimctl types\" to list types", args[0])
+	}
+	if err := decodePayload(args[1], info.NewData()); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "valid %s\n", args[0])
+	return nil
+}
+
+func (c *ctl) types() error {
+	var events, commands []uim.EventTypeInfo
+	for _, info := range uim.EventTypes() {
+		if info.IsCommand() {
+			commands = append(commands, info)
+		} else {
+			events = append(events, info)
+		}
+	}
+	fmt.Fprintln(c.stdout, "Events:")
+	for _, info := range events {
+		fmt.Fprintf(c.stdout, "  %-36s %s\n", info.Type, dataTypeName(info))
+	}
+	fmt.Fprintln(c.stdout, "Commands:")
+	for _, info := range commands {
+		fmt.Fprintf(c.stdout, "  %-36s %s\n", info.Type, dataTypeName(info))
+	}
+	return nil
+}
+
+// 数据类型名，没有定义数据模型时为 -
+func dataTypeName(info uim.EventTypeInfo) string {
+	if info.Data == nil {
+		return "-"
+	}
+	return "*" + info.Data.String()
+}
+
+// 读取事件数据，数据中不能包含模型中没有定义的字段，并且需要通过模型的校验
+func decodePayload(path string, data any) error {
+	content, err := readPayload(path)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(data); err != nil {
+		return fmt.Errorf("%s is not a valid %T: %v", path, data, err)
+	}
+	if validator, ok := data.(uim.Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return fmt.Errorf("%s is not a valid %T: %v", path, data, err)
+		}
+	}
+	return nil
+}
+
+func (c *ctl) print(v any) error {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.printJSON(content)
+}
+
+// 格式化输出 JSON，输出到终端时使用颜色
+func (c *ctl) printJSON(content []byte) error {
+	if len(bytes.TrimSpace(content)) == 0 {
+		return nil
+	}
+	pretty, err := prettyFormat(content, isTerminal(c.stdout))
+	if err != nil {
+		pretty = content
+	}
+	_, err = fmt.Fprintln(c.stdout, string(pretty))
+	return err
+}
+
+func prettyFormat(content []byte, color bool) ([]byte, error) {
+	formatter := prettyjson.NewFormatter()
+	formatter.DisabledColor = !color
+	return formatter.Format(content)
+}
+
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	uim "github.com/uimkit/provider-go"
+	"github.com/uimkit/provider-go/uimtest/issuer"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestToken(t *testing.T) {
+	fake := issuer.New("uimctl")
+	defer fake.Close()
+
+	config := writeFile(t, "uimctl.yaml", "client_id: c1\nclient_secret: s1\nclient_audience: uimctl\n")
+	stdout := new(bytes.Buffer)
+	err := run([]string{"token", "-config", config, "-token-endpoint", fake.TokenEndpoint()}, stdout, io.Discard)
+	assert.Nil(t, err)
+
+	result := struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}{}
+	assert.Nil(t, json.Unmarshal(stdout.Bytes(), &result))
+	assert.NotEmpty(t, result.AccessToken)
+	assert.Equal(t, int64(3600), result.ExpiresIn)
+}
+
+func TestSendAndInvoke(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+		if strings.Contains(string(body), uim.UIMCommandGetChannelInfo) {
+			_, _ = w.Write([]byte(`{"user":{"user_id":"u1"}}`))
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("UIM_BASE_URL", server.URL)
+	t.Setenv("UIM_ENABLE_AUTHORIZATION", "false")
+	t.Setenv("UIM_EVENT_SOURCE", "provider.source/test/test")
+
+	message := writeFile(t, "message.yaml", "message_id: m1\nchannel: c1\naccount: a1\nuser_id: u1\ntype: text\ntext: hello\n")
+	stdout := new(bytes.Buffer)
+	assert.Nil(t, run([]string{"send", uim.ProviderEventNewMessage, message}, stdout, io.Discard))
+	assert.Equal(t, "sent "+uim.ProviderEventNewMessage+"\n", stdout.String())
+	assert.Len(t, received, 1)
+	assert.True(t, strings.Contains(received[0], `"text":"hello"`), received[0])
+
+	request := writeFile(t, "request.json", `{"channel":"c1"}`)
+	stdout.Reset()
+	assert.Nil(t, run([]string{"invoke", uim.UIMCommandGetChannelInfo, request}, stdout, io.Discard))
+	assert.Equal(t, "{\n  \"user\": {\n    \"user_id\": \"u1\"\n  }\n}\n", stdout.String())
+}
+
+func TestValidate(t *testing.T) {
+	valid := writeFile(t, "message.json", `{"message_id":"m1","channel":"c1","account":"a1","user_id":"u1","type":"text","text":"hello"}`)
+	stdout := new(bytes.Buffer)
+	assert.Nil(t, run([]string{"validate", uim.ProviderEventNewMessage, valid}, stdout, io.Discard))
+	assert.Equal(t, "valid "+uim.ProviderEventNewMessage+"\n", stdout.String())
+
+	invalid := writeFile(t, "message.json", `{"message_id":"m1","txt":"hello"}`)
+	err := run([]string{"validate", uim.ProviderEventNewMessage, invalid}, io.Discard, io.Discard)
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), `unknown field "txt"`), err.Error())
+
+	invalid = writeFile(t, "message.json", `{"message_id":"m1","channel":"c1","account":"a1","user_id":"u1","type":"image","text":"hello"}`)
+	err = run([]string{"validate", uim.ProviderEventNewMessage, invalid}, io.Discard, io.Discard)
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "image: is required for image message"), err.Error())
+
+	err = run([]string{"validate", "provider.unknown", valid}, io.Discard, io.Discard)
+	assert.NotNil(t, err)
+}
+
+func TestSchema(t *testing.T) {
+	dir := t.TempDir()
+	stdout := new(bytes.Buffer)
+	assert.Nil(t, run([]string{"schema", "-out", dir}, stdout, io.Discard))
+	assert.Equal(t, "wrote asyncapi.json\nwrote uim.schema.json\n", stdout.String())
+	content, err := os.ReadFile(filepath.Join(dir, "asyncapi.json"))
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(string(content), uim.UIMCommandSendMessage))
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+
+	"github.com/uimkit/provider-go/schema"
+)
+
+// 生成事件和指令数据的 JSON Schema 和 AsyncAPI 文档
+func (c *ctl) schema(args []string) error {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: uimctl schema [flags]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	out := fs.String("out", "", "write "+schema.JSONSchemaFile+" and "+schema.AsyncAPIFile+" to this directory, print the JSON Schema if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return errors.New("schema takes no arguments")
+	}
+	files, err := schema.Files()
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = c.stdout.Write(files[schema.JSONSchemaFile])
+		return err
+	}
+	if err := schema.Write(*out); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(c.stdout, "wrote %s\n", name)
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package uim
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// 环境变量前缀，如：UIM_CLIENT_ID
+const EnvPrefix = "UIM_"
+
+// 配置项 base_url 会被拆分为 scheme、domain、port 和 base_path
+const baseUrlConfigKey = "base_url"
+
+// 从配置文件和环境变量读取 Options，环境变量覆盖配置文件，opts 覆盖环境变量
+// 配置文件支持 YAML、JSON、TOML，根据扩展名判断格式，path 为空时只读取环境变量
+// 配置项为 Options 字段名的蛇形命名，环境变量为 UIM_ 加配置项的大写，如：
+//
+//	client_id: UIM_CLIENT_ID
+//	base_url: UIM_BASE_URL，拆分为 scheme、domain、port 和 base_path
+//	read_timeout: UIM_READ_TIMEOUT，时间使用 Go 的 duration 格式，如：30s，也可以是纳秒数
+//	http_proxy、https_proxy、no_proxy: UIM_HTTP_PROXY、UIM_HTTPS_PROXY、UIM_NO_PROXY
+//	auto_retry、max_retry_time: UIM_AUTO_RETRY、UIM_MAX_RETRY_TIME
+//	enable_async、max_task_queue_size、go_routine_pool_size: UIM_ENABLE_ASYNC、UIM_MAX_TASK_QUEUE_SIZE、UIM_GO_ROUTINE_POOL_SIZE
+//
+// 接口类型的字段（如：Transport、Outbox、Metrics）不能通过配置设置，需要使用 opts
+// 读取或校验失败时返回 SDK.InvalidOptions 错误，包含所有无效的配置项
+func LoadOptions(path string, opts ...Option) (*Options, error) {
+	options := NewOptions()
+	var errs []string
+	if path != "" {
+		values, err := readConfigFile(path)
+		if err != nil {
+			return nil, NewClientError(InvalidOptionsErrorCode, fmt.Sprintf(InvalidOptionsErrorMessage, err.Error()), err)
+		}
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, ok := configValue(values[key])
+			if !ok {
+				errs = append(errs, fmt.Sprintf("%s: must be a string, number or boolean", key))
+				continue
+			}
+			if err := setOption(options, key, value); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", key, err))
+			}
+		}
+	}
+	for _, key := range configKeys() {
+		env := EnvPrefix + strings.ToUpper(key)
+		if value, ok := os.LookupEnv(env); ok {
+			if err := setOption(options, key, value); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", env, err))
+			}
+		}
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	errs = append(errs, options.validate()...)
+	if len(errs) > 0 {
+		return options, NewClientError(InvalidOptionsErrorCode, fmt.Sprintf(InvalidOptionsErrorMessage, strings.Join(errs, "; ")), nil)
+	}
+	return options, nil
+}
+
+// 使用 LoadOptions 读取的 Options
+func WithOptions(options *Options) Option {
+	return func(o *Options) {
+		*o = *options
+	}
+}
+
+func readConfigFile(path string) (map[string]any, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]any)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &values)
+	case ".toml":
+		err = toml.Unmarshal(content, &values)
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		err = decoder.Decode(&values)
+	default:
+		return nil, fmt.Errorf("unsupported config file %s, must be .yaml, .yml, .json or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return values, nil
+}
+
+// 配置文件中的值转换为字符串，只支持标量
+func configValue(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case bool, int, int64, uint64, float64, json.Number:
+		return fmt.Sprint(v), true
+	default:
+		return "", false
+	}
+}
+
+// Options 字段名转换为配置项，如：ClientId 转换为 client_id
+func configKey(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && !unicode.IsUpper(runes[i-1]) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// 可以通过配置设置的字段
+func isConfigurable(field reflect.StructField) bool {
+	switch field.Type.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// 所有配置项
+func configKeys() []string {
+	keys := []string{baseUrlConfigKey}
+	optionsType := reflect.TypeOf(Options{})
+	for i := 0; i < optionsType.NumField(); i++ {
+		if field := optionsType.Field(i); isConfigurable(field) {
+			keys = append(keys, configKey(field.Name))
+		}
+	}
+	return keys
+}
+
+func setOption(options *Options, key, value string) error {
+	key = strings.ToLower(key)
+	if key == baseUrlConfigKey {
+		if _, err := url.Parse(value); err != nil {
+			return err
+		}
+		WithBaseUrl(value)(options)
+		return nil
+	}
+	optionsType := reflect.TypeOf(options).Elem()
+	for i := 0; i < optionsType.NumField(); i++ {
+		field := optionsType.Field(i)
+		if configKey(field.Name) != key {
+			continue
+		}
+		if !isConfigurable(field) {
+			return fmt.Errorf("can not be set by config, use With%s option", field.Name)
+		}
+		return setFieldValue(reflect.ValueOf(options).Elem().Field(i), value)
+	}
+	return fmt.Errorf("unknown option")
+}
+
+// 校验 Options，返回 SDK.InvalidOptions 错误，包含所有无效的字段
+func (options *Options) Validate() error {
+	if errs := options.validate(); len(errs) > 0 {
+		return NewClientError(InvalidOptionsErrorCode, fmt.Sprintf(InvalidOptionsErrorMessage, strings.Join(errs, "; ")), nil)
+	}
+	return nil
+}
+
+func (options *Options) validate() (errs []string) {
+	invalid := func(key, format string, args ...any) {
+		errs = append(errs, key+": "+fmt.Sprintf(format, args...))
+	}
+	validUrl := func(key, value string) {
+		if value == "" {
+			return
+		}
+		if parsed, err := url.Parse(value); err != nil {
+			invalid(key, "%v", err)
+		} else if parsed.Scheme == "" || parsed.Host == "" {
+			invalid(key, "must be an absolute url")
+		}
+	}
+
+	validUrl("server_issuer", options.ServerIssuer)
+	validUrl("token_endpoint", options.TokenEndpoint)
+	validUrl("http_proxy", options.HttpProxy)
+	validUrl("https_proxy", options.HttpsProxy)
+	if options.NoProxy != "" {
+		for _, pattern := range strings.Split(options.NoProxy, ",") {
+			if _, err := regexp.Compile(pattern); err != nil {
+				invalid("no_proxy", "%v", err)
+			}
+		}
+	}
+	if scheme := strings.ToUpper(options.Scheme); scheme != HTTP && scheme != HTTPS {
+		invalid("scheme", "must be HTTP or HTTPS")
+	}
+	if options.Domain == "" {
+		invalid("domain", "must not be empty")
+	}
+	if options.Port < 0 || options.Port > 65535 {
+		invalid("port", "must be between 0 and 65535")
+	}
+	if options.MaxRetryTime < 0 {
+		invalid("max_retry_time", "must not be negative")
+	}
+	if options.DebugBodyLimit < 0 {
+		invalid("debug_body_limit", "must not be negative")
+	}
+	if options.MaxTaskQueueSize < 1 {
+		invalid("max_task_queue_size", "must be positive")
+	}
+	if options.GoRoutinePoolSize < 1 {
+		invalid("go_routine_pool_size", "must be positive")
+	}
+	if options.ReadTimeout < 0 {
+		invalid("read_timeout", "must not be negative")
+	}
+	if options.ConnectTimeout < 0 {
+		invalid("connect_timeout", "must not be negative")
+	}
+	if options.OutboxMaxAttempts < 1 {
+		invalid("outbox_max_attempts", "must be positive")
+	}
+	if options.OutboxMinBackoff <= 0 {
+		invalid("outbox_min_backoff", "must be positive")
+	}
+	if options.OutboxMaxBackoff < options.OutboxMinBackoff {
+		invalid("outbox_max_backoff", "must not be less than outbox_min_backoff")
+	}
+	if options.OrderingKey != OrderingKeyChannel && options.OrderingKey != OrderingKeyAccount {
+		invalid("ordering_key", "must be %s or %s", OrderingKeyChannel, OrderingKeyAccount)
+	}
+	if options.RateLimit < 0 {
+		invalid("rate_limit", "must not be negative")
+	}
+	if options.RateBurst < 1 {
+		invalid("rate_burst", "must be positive")
+	}
+	if options.AccountRateLimit < 0 {
+		invalid("account_rate_limit", "must not be negative")
+	}
+	if options.AccountRateBurst < 1 {
+		invalid("account_rate_burst", "must be positive")
+	}
+	return
+}
@@ -0,0 +1,84 @@
+package uim
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadOptions(t *testing.T) {
+	yamlConfig := writeConfig(t, "uim.yaml", `
+client_id: c1
+client_secret: s1
+base_url: http://127.0.0.1:9000/providers/v1
+read_timeout: 30s
+connect_timeout: 1000000000
+auto_retry: true
+max_retry_time: 5
+https_proxy: http://proxy:8080
+rate_limit: 2.5
+`)
+	t.Setenv("UIM_CLIENT_SECRET", "s2")
+	t.Setenv("UIM_ENABLE_ASYNC", "true")
+
+	options, err := LoadOptions(yamlConfig, WithEventSource("provider.source/test/test"))
+	assert.Nil(t, err)
+	assert.Equal(t, "c1", options.ClientId)
+	assert.Equal(t, "s2", options.ClientSecret)
+	assert.Equal(t, "http", options.Scheme)
+	assert.Equal(t, "127.0.0.1", options.Domain)
+	assert.Equal(t, int32(9000), options.Port)
+	assert.Equal(t, "/providers/v1", options.BasePath)
+	assert.Equal(t, 30*time.Second, options.ReadTimeout)
+	assert.Equal(t, time.Second, options.ConnectTimeout)
+	assert.True(t, options.AutoRetry)
+	assert.Equal(t, int32(5), options.MaxRetryTime)
+	assert.Equal(t, "http://proxy:8080", options.HttpsProxy)
+	assert.Equal(t, 2.5, options.RateLimit)
+	assert.True(t, options.EnableAsync)
+	assert.Equal(t, "provider.source/test/test", options.EventSource)
+	// 未设置的使用默认值
+	assert.Equal(t, int32(1000), options.MaxTaskQueueSize)
+
+	tomlConfig := writeConfig(t, "uim.toml", "client_id = \"c3\"\nmax_retry_time = 2\n")
+	options, err = LoadOptions(tomlConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, "c3", options.ClientId)
+	assert.Equal(t, int32(2), options.MaxRetryTime)
+
+	jsonConfig := writeConfig(t, "uim.json", `{"client_id":"c4","read_timeout":300000000000}`)
+	options, err = LoadOptions(jsonConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, "c4", options.ClientId)
+	assert.Equal(t, 300*time.Second, options.ReadTimeout)
+}
+
+func TestLoadOptionsInvalid(t *testing.T) {
+	config := writeConfig(t, "uim.yaml", `
+read_timeout: soon
+port: 70000
+auto_retry: maybe
+transport: http
+unknown: 1
+ordering_key: user
+`)
+	t.Setenv("UIM_MAX_RETRY_TIME", "-1")
+
+	_, err := LoadOptions(config)
+	assert.NotNil(t, err)
+	assert.Equal(t, InvalidOptionsErrorCode, err.(Error).ErrorCode())
+	// 所有无效的配置项都会列出
+	for _, key := range []string{"read_timeout", "port", "auto_retry", "transport", "unknown", "ordering_key", "max_retry_time"} {
+		assert.True(t, strings.Contains(err.Error(), key+":"), "%s: %s", key, err.Error())
+	}
+}
@@ -0,0 +1,153 @@
+package uim
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hokaccha/go-prettyjson"
+)
+
+// 输出请求和响应的原始内容，用于调试
+// 调用 UIM 时 > 表示发出的请求，< 表示收到的响应；收到事件时 < 表示收到的请求，> 表示返回的响应
+type wireDumper struct {
+	lock      sync.Mutex
+	out       io.Writer
+	bodyLimit int
+	formatter *prettyjson.Formatter
+}
+
+func newWireDumper(out io.Writer, bodyLimit int) *wireDumper {
+	if out == nil {
+		out = os.Stdout
+	}
+	formatter := prettyjson.NewFormatter()
+	formatter.DisabledColor = true
+	return &wireDumper{
+		out:       out,
+		bodyLimit: bodyLimit,
+		formatter: formatter,
+	}
+}
+
+// 环境变量 DEBUG 中是否包含 flag，多个 flag 用逗号分隔，如：DEBUG=sdk
+func isDebugEnabled(flag string) bool {
+	for _, part := range strings.Split(os.Getenv("DEBUG"), ",") {
+		if part == flag {
+			return true
+		}
+	}
+	return false
+}
+
+func (dumper *wireDumper) writeHeaders(buf *bytes.Buffer, prefix string, header http.Header) {
+	header = redactHeaders(header)
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(buf, "%s %s: %s\n", prefix, key, strings.Join(header[key], ", "))
+	}
+	fmt.Fprintf(buf, "%s\n", prefix)
+}
+
+// JSON 格式化后输出，超过长度限制时截断
+func (dumper *wireDumper) writeBody(buf *bytes.Buffer, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	if pretty, err := dumper.formatter.Format(body); err == nil {
+		body = pretty
+	}
+	if dumper.bodyLimit > 0 && len(body) > dumper.bodyLimit {
+		fmt.Fprintf(buf, "%s\n... (%d bytes truncated)\n", body[:dumper.bodyLimit], len(body)-dumper.bodyLimit)
+		return
+	}
+	buf.Write(body)
+	buf.WriteString("\n")
+}
+
+func (dumper *wireDumper) write(buf *bytes.Buffer) {
+	dumper.lock.Lock()
+	defer dumper.lock.Unlock()
+	_, _ = dumper.out.Write(buf.Bytes())
+}
+
+func (dumper *wireDumper) dumpRequest(prefix string, request *http.Request, body []byte, note string) {
+	buf := new(bytes.Buffer)
+	if note != "" {
+		fmt.Fprintf(buf, "* %s\n", note)
+	}
+	fmt.Fprintf(buf, "%s %s %s %s\n", prefix, request.Method, request.URL.RequestURI(), request.Proto)
+	fmt.Fprintf(buf, "%s Host: %s\n", prefix, request.Host)
+	dumper.writeHeaders(buf, prefix, request.Header)
+	dumper.writeBody(buf, body)
+	dumper.write(buf)
+}
+
+func (dumper *wireDumper) dumpResponse(prefix string, proto string, status int, header http.Header, body []byte) {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "%s %s %d %s\n", prefix, proto, status, http.StatusText(status))
+	dumper.writeHeaders(buf, prefix, header)
+	dumper.writeBody(buf, body)
+	dumper.write(buf)
+}
+
+func (dumper *wireDumper) dumpError(err error) {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "* Error: %s\n", err.Error())
+	dumper.write(buf)
+}
+
+// 记录返回的响应，处理完成后输出
+type dumpResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *dumpResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *dumpResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// 输出调用 UIM 的请求
+func (client *Client) dumpRequest(request *http.Request, body []byte, retryTimes int) {
+	if client.dumper == nil {
+		return
+	}
+	note := ""
+	if retryTimes > 0 {
+		note = fmt.Sprintf("Retry Times: %d", retryTimes)
+	}
+	client.dumper.dumpRequest(">", request, body, note)
+}
+
+// 输出 UIM 的响应，body 为空时只输出状态和 header
+func (client *Client) dumpResponse(response *http.Response, body []byte) {
+	if client.dumper == nil {
+		return
+	}
+	client.dumper.dumpResponse("<", response.Proto, response.StatusCode, response.Header, body)
+}
+
+func (client *Client) dumpError(err error) {
+	if client.dumper != nil && err != nil {
+		client.dumper.dumpError(err)
+	}
+}
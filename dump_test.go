@@ -0,0 +1,47 @@
+package uim
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWireDump(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"message_id":"m1","content":"` + strings.Repeat("x", 100) + `"}`))
+	}))
+	defer server.Close()
+
+	out := new(bytes.Buffer)
+	client := NewClient(
+		WithAuthorization(false),
+		WithEventSource("provider.source/test/test"),
+		WithBaseUrl(server.URL),
+		WithDebug(true),
+		WithDebugOutput(out, 64),
+	)
+	req := NewBaseRequest()
+	req.AddHeaderParam("Authorization", "Bearer secret-token")
+	req.SetContent([]byte(`{"type":"test"}`))
+	assert.Nil(t, client.DoAction(req, &BaseResponse{}))
+
+	dump := out.String()
+	assert.True(t, strings.Contains(dump, "> Authorization: "+RedactedValue), dump)
+	assert.False(t, strings.Contains(dump, "secret-token"), dump)
+	assert.True(t, strings.Contains(dump, "{\n  \"type\": \"test\"\n}"), dump)
+	assert.True(t, strings.Contains(dump, "< HTTP/1.1 200 OK"), dump)
+	assert.True(t, strings.Contains(dump, "bytes truncated)"), dump)
+
+	// 收到的事件
+	out.Reset()
+	recorder := httptest.NewRecorder()
+	client.EventHandler()(recorder, httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(`{"type":"test"}`)))
+	dump = out.String()
+	assert.True(t, strings.Contains(dump, "< POST /events HTTP/1.1"), dump)
+	assert.True(t, strings.Contains(dump, "> HTTP/1.1 401 Unauthorized"), dump)
+}
@@ -3,7 +3,10 @@ package uim
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type Error interface {
@@ -39,6 +42,21 @@ const (
 
 	AuthenticationFailedErrorCode    = "SDK.AuthenticationFailed"
 	AuthenticationFailedErrorMessage = "Authentication failed, please check 'client_id' & 'client_secret'"
+
+	CircuitOpenErrorCode    = "SDK.CircuitOpen"
+	CircuitOpenErrorMessage = "Circuit breaker is open, requests to UIM are rejected until it recovers"
+
+	OutboxNotEnabledErrorCode    = "SDK.OutboxNotEnabled"
+	OutboxNotEnabledErrorMessage = "Outbox is not enabled in client, please set 'WithOutbox' option"
+
+	OutboxWriteErrorCode    = "SDK.OutboxWriteError"
+	OutboxWriteErrorMessage = "Failed to write event to outbox"
+
+	OutboxEntryNotFoundErrorCode    = "SDK.OutboxEntryNotFound"
+	OutboxEntryNotFoundErrorMessage = "Dead letter \"%s\" is not found in outbox"
+
+	InvalidOptionsErrorCode    = "SDK.InvalidOptions"
+	InvalidOptionsErrorMessage = "Invalid options: %s"
 )
 
 type ClientError struct {
@@ -104,8 +122,18 @@ const (
 	UnsupportedResponseFormatErrorCode    = "SDK.UnsupportedResponseFormat"
 	UnsupportedResponseFormatErrorMessage = "Could not marshal response in \"%s\", pelease set proper \"Accept\" header in request"
 
-	InvalidEventDataErrorStatus = http.StatusBadRequest
-	InvalidEventDataErrorCode   = "SDK.InvalidEventData"
+	InvalidEventDataErrorStatus  = http.StatusBadRequest
+	InvalidEventDataErrorCode    = "SDK.InvalidEventData"
+	InvalidEventDataErrorMessage = "Invalid event data: %s"
+
+	MismatchedEventDataErrorStatus  = http.StatusInternalServerError
+	MismatchedEventDataErrorCode    = "SDK.MismatchedEventData"
+	MismatchedEventDataErrorMessage = "\"%s\" requires %s of type *%s, got %T"
+	MismatchedEventKindErrorMessage = "\"%s\" is %s, please use %s"
+
+	InvalidResponseDataErrorStatus  = http.StatusInternalServerError
+	InvalidResponseDataErrorCode    = "SDK.InvalidResponseData"
+	InvalidResponseDataErrorMessage = "Invalid response of \"%s\": %s"
 
 	ResourceNotFoundErrorStatus = http.StatusNotFound
 	ResourceNotFoundErrorCode   = "SDK.ResourceNotFound"
@@ -117,6 +145,10 @@ const (
 	AccountProviderMismatchErrorStatus  = http.StatusBadRequest
 	AccountProviderMismatchErrorCode    = "SDK.AccountProviderMismatch"
 	AccountProviderMismatchErrorMessage = "Account is currently using another provider"
+
+	TooManyRequestsErrorStatus  = http.StatusTooManyRequests
+	TooManyRequestsErrorCode    = "SDK.TooManyRequests"
+	TooManyRequestsErrorMessage = "Too many requests for account \"%s\", please retry later"
 )
 
 type ServerError struct {
@@ -124,6 +156,7 @@ type ServerError struct {
 	errorCode   string
 	message     string
 	originError error
+	retryAfter  time.Duration
 }
 
 func NewServerError(httpStatus int, errorCode, message string, originError error) Error {
@@ -135,6 +168,17 @@ func NewServerError(httpStatus int, errorCode, message string, originError error
 	}
 }
 
+// 创建可以在 retryAfter 之后重试的服务端错误，响应时会设置 Retry-After 头
+func NewRetryableServerError(httpStatus int, errorCode, message string, retryAfter time.Duration, originError error) Error {
+	return &ServerError{
+		httpStatus:  httpStatus,
+		errorCode:   errorCode,
+		message:     message,
+		originError: originError,
+		retryAfter:  retryAfter,
+	}
+}
+
 func (err *ServerError) Error() string {
 	serverErrMsg := fmt.Sprintf("[%s] %s", err.ErrorCode(), err.message)
 	if err.originError != nil {
@@ -159,9 +203,14 @@ func (err *ServerError) Message() string {
 	return err.message
 }
 
-func parseServerError(httpStatus int, responseContent string) Error {
+func (err *ServerError) RetryAfter() time.Duration {
+	return err.retryAfter
+}
+
+func parseServerError(httpStatus int, header http.Header, responseContent string) Error {
 	result := &ServerError{
 		httpStatus: httpStatus,
+		retryAfter: parseRetryAfter(header.Get("Retry-After")),
 	}
 	data := make(map[string]string)
 	err := json.Unmarshal([]byte(responseContent), &data)
@@ -175,12 +224,24 @@ func parseServerError(httpStatus int, responseContent string) Error {
 	return result
 }
 
+// 解析 Retry-After 头，只支持秒数
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
 func writeError(w http.ResponseWriter, err error) {
 	if serverError, ok := err.(*ServerError); ok {
 		b, _ := json.Marshal(map[string]string{
 			"code":    serverError.errorCode,
 			"message": serverError.message,
 		})
+		if serverError.retryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(serverError.retryAfter.Seconds()))))
+		}
 		w.WriteHeader(serverError.httpStatus)
 		w.Write(b)
 	} else {
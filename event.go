@@ -13,6 +13,10 @@ const (
 	ProviderEventNewMetafield     = "provider.new_metafield"     // 新的元信息
 	ProviderEventMetafieldUpdated = "provider.metafield_updated" // 元信息更新
 
+	ProviderEventNewMessageReaction     = "provider.new_message_reaction"     // 收到消息的表情回应
+	ProviderEventMessageReactionDeleted = "provider.message_reaction_deleted" // 消息的表情回应被取消
+	ProviderEventMessageStatusChanged   = "provider.message_status_changed"   // 消息的投递状态变更，如：送达、已读
+
 	ProviderEventNewFriendReply       = "provider.new_friend_reply"       // 收到好友申请回复
 	ProviderEventNewGroup             = "provider.new_group"              // 新群组
 	ProviderEventGroupUpdated         = "provider.group_updated"          // 群组更新
@@ -49,6 +53,14 @@ const (
 	UIMCommandGetMomentList     = "uim.get_moment_list"     // 获取动态列表
 	UIMCommandSetGroupMute      = "uim.set_group_mute"      // 设置群组禁言
 	UIMCommandPublishMoment     = "uim.publish_moment"      // 发布动态
+	UIMCommandRevokeMessage     = "uim.revoke_message"      // 撤回消息
+	UIMCommandEditMessage       = "uim.edit_message"        // 编辑消息
+	UIMCommandDeleteMessage     = "uim.delete_message"      // 删除消息
+	UIMCommandForwardMessage    = "uim.forward_message"     // 转发消息
+	UIMCommandReactMessage      = "uim.react_message"       // 回应或取消回应消息的表情
+	UIMCommandMarkRead          = "uim.mark_read"           // 将会话标记为已读
+	UIMCommandGetCapabilities   = "uim.get_capabilities"    // 查询 Provider 支持的能力，由 EventHandler 自动回复
+	UIMCommandPing              = "uim.ping"                // 验证 UIM 到 Provider 的连接和认证，由 EventHandler 自动回复
 
 	// deprecated
 	UIMCommandUpdateAccount         = "uim.update_account"          // 更新账号资料
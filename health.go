@@ -0,0 +1,128 @@
+package uim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/authok/go-jwt-middleware/v2/jwks"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// 健康状态
+type HealthStatus string
+
+const (
+	HealthStatusOK          HealthStatus = "ok"          // 正常
+	HealthStatusDegraded    HealthStatus = "degraded"    // 可以接收事件，但调用 UIM 受影响，如：熔断、死信
+	HealthStatusUnavailable HealthStatus = "unavailable" // 无法获取 token 或 JWKS，返回 503
+)
+
+// 单项检查的结果
+type HealthCheck struct {
+	Status HealthStatus `json:"status"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// 异步任务队列
+type AsyncQueueHealth struct {
+	Depth    int `json:"depth"`    // 队列长度
+	Capacity int `json:"capacity"` // 队列容量
+}
+
+// 发件箱积压
+type OutboxHealth struct {
+	HealthCheck
+	Pending int `json:"pending"` // 待投递的事件数
+	Failed  int `json:"failed"`  // 死信数
+}
+
+// 健康检查的结果
+type Health struct {
+	Status       HealthStatus      `json:"status"`
+	Token        *HealthCheck      `json:"token,omitempty"`       // 获取调用 UIM 的 access token，未开启认证时为空
+	JWKS         *HealthCheck      `json:"jwks,omitempty"`        // 获取验证事件 token 的 JWKS，未开启事件认证时为空
+	AsyncQueue   *AsyncQueueHealth `json:"async_queue,omitempty"` // 未开启异步时为空
+	Outbox       *OutboxHealth     `json:"outbox,omitempty"`      // 未设置发件箱时为空
+	CircuitState string            `json:"circuit_state"`         // 调用 UIM 的熔断器状态
+}
+
+// 健康检查的超时时间
+const healthCheckTimeout = 5 * time.Second
+
+// 检查 token、JWKS、异步队列、发件箱和熔断器
+func (client *Client) Health(ctx context.Context) *Health {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	health := &Health{Status: HealthStatusOK, CircuitState: client.CircuitState().String()}
+	degrade := func(status HealthStatus) {
+		if status == HealthStatusUnavailable || health.Status == HealthStatusOK {
+			health.Status = status
+		}
+	}
+	check := func(err error) *HealthCheck {
+		if err != nil {
+			degrade(HealthStatusUnavailable)
+			return &HealthCheck{Status: HealthStatusUnavailable, Error: err.Error()}
+		}
+		return &HealthCheck{Status: HealthStatusOK}
+	}
+
+	if client.options.EnableAuthorization {
+		_, err := client.getAccessToken(ctx)
+		health.Token = check(err)
+	}
+	if client.options.EventAuthorization {
+		health.JWKS = check(client.checkJWKS(ctx))
+	}
+	if client.isOpenAsync {
+		health.AsyncQueue = &AsyncQueueHealth{Depth: len(client.asyncTaskQueue), Capacity: cap(client.asyncTaskQueue)}
+		if health.AsyncQueue.Depth >= health.AsyncQueue.Capacity {
+			degrade(HealthStatusDegraded)
+		}
+	}
+	if client.outbox != nil {
+		pending, failed, err := client.OutboxStats()
+		health.Outbox = &OutboxHealth{HealthCheck: *check(err), Pending: pending, Failed: failed}
+		if failed > 0 {
+			degrade(HealthStatusDegraded)
+		}
+	}
+	if health.CircuitState != CircuitStateClosed.String() {
+		degrade(HealthStatusDegraded)
+	}
+	return health
+}
+
+// 获取验证事件 token 的公钥
+func (client *Client) checkJWKS(ctx context.Context) error {
+	issuerURL, err := url.Parse(client.options.ServerIssuer)
+	if err != nil {
+		return err
+	}
+	_, err = jwks.NewCachingProvider(issuerURL, 5*time.Minute).KeyFunc(ctx)
+	return err
+}
+
+// 健康检查的 http handler，供负载均衡探测，不需要认证，不可用时返回 503
+func (client *Client) HealthHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		health := client.Health(r.Context())
+		body, _ := json.Marshal(health)
+		w.Header().Set("Content-Type", Json)
+		if health.Status == HealthStatusUnavailable {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		_, _ = w.Write(body)
+	}
+}
+
+// 回复 uim.ping，收到时已经通过事件认证
+func (c *Client) ping(_ *cloudevents.Event) (any, error) {
+	return &PingResponse{EventSource: c.options.EventSource, Version: Version}, nil
+}
@@ -0,0 +1,90 @@
+package uim
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getHealth(t *testing.T, client *Client) (int, *Health) {
+	recorder := httptest.NewRecorder()
+	client.HealthHandler()(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	health := &Health{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), health))
+	return recorder.Code, health
+}
+
+func TestHealthHandler(t *testing.T) {
+	var issuer *httptest.Server
+	issuer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth/token":
+			_, _ = w.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+		case "/.well-known/openid-configuration":
+			_, _ = w.Write([]byte(`{"jwks_uri":"` + issuer.URL + `/.well-known/jwks.json"}`))
+		case "/.well-known/jwks.json":
+			_, _ = w.Write([]byte(`{"keys":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer issuer.Close()
+
+	client := NewClient(
+		WithClient("id", "secret", "uim"),
+		WithTokenEndpoint(issuer.URL+"/oauth/token"),
+		WithServer(issuer.URL+"/", "provider"),
+		WithAsync(true, 10, 1),
+	)
+	defer client.Shutdown()
+	status, health := getHealth(t, client)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, HealthStatusOK, health.Status)
+	assert.Equal(t, HealthStatusOK, health.Token.Status)
+	assert.Equal(t, HealthStatusOK, health.JWKS.Status)
+	assert.Equal(t, 10, health.AsyncQueue.Capacity)
+	assert.Nil(t, health.Outbox)
+	assert.Equal(t, "closed", health.CircuitState)
+
+	// 获取 token 失败时不可用
+	client = NewClient(
+		WithClient("id", "secret", "uim"),
+		WithTokenEndpoint(issuer.URL+"/missing"),
+		WithEventAuthorization(false),
+	)
+	status, health = getHealth(t, client)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, HealthStatusUnavailable, health.Status)
+	assert.NotEmpty(t, health.Token.Error)
+	assert.Nil(t, health.JWKS)
+}
+
+func TestHealthOutbox(t *testing.T) {
+	outbox, err := NewFileOutbox(t.TempDir())
+	assert.Nil(t, err)
+	assert.Nil(t, outbox.Save(&OutboxEntry{ID: "e1", Type: ProviderEventNewMessage, State: OutboxEntryStateFailed}))
+
+	client := NewClient(WithAuthorization(false), WithEventAuthorization(false), WithOutbox(outbox))
+	defer client.Shutdown()
+	status, health := getHealth(t, client)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, HealthStatusDegraded, health.Status)
+	assert.Equal(t, 1, health.Outbox.Failed)
+	assert.Nil(t, health.Token)
+}
+
+func TestPing(t *testing.T) {
+	provider := NewClient(WithEventAuthorization(false), WithEventSource("provider.source/test/test"))
+	server := httptest.NewServer(provider.EventHandler())
+	defer server.Close()
+
+	client := NewClient(WithAuthorization(false), WithEventSource("uim/test"), WithBaseUrl(server.URL))
+	resp, err := CastCommandResponse[*PingResponse](client.Invoke(UIMCommandPing, &PingRequest{}, &PingResponse{}))
+	assert.Nil(t, err)
+	assert.Equal(t, "provider.source/test/test", resp.EventSource)
+	assert.Equal(t, Version, resp.Version)
+	assert.Empty(t, provider.HandledEventTypes())
+}
@@ -1,16 +1,59 @@
 package uim
 
 import (
-	"fmt"
 	"io"
 	"log"
 	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
+// 日志级别
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+func (level LogLevel) String() string {
+	switch level {
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelWarn:
+		return "warn"
+	case LogLevelError:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// 解析日志级别，无法识别时为 info
+func ParseLogLevel(level string) LogLevel {
+	switch strings.ToLower(level) {
+	case "debug":
+		return LogLevelDebug
+	case "warn", "warning":
+		return LogLevelWarn
+	case "error":
+		return LogLevelError
+	default:
+		return LogLevelInfo
+	}
+}
+
+// 单个请求的日志回调，收到该请求每一次调用格式化后的日志，不受日志级别和开关的影响
+type LogHook func(level LogLevel, msg string)
+
 type Logger struct {
 	*log.Logger
+	lock           sync.Mutex
+	level          LogLevel
+	loggers        map[LogLevel]*log.Logger
 	formatTemplate string
 	isOpen         bool
 	lastLogMsg     string
@@ -26,95 +69,132 @@ func initLogMsg(fieldMap map[string]string) {
 }
 
 func (client *Client) GetLogger() *Logger {
+	client.loggerLock.RLock()
+	defer client.loggerLock.RUnlock()
 	return client.logger
 }
 
-func (client *Client) GetLoggerMsg() string {
+// 获取日志，没有设置时创建默认的日志
+func (client *Client) getOrCreateLogger() *Logger {
+	if logger := client.GetLogger(); logger != nil {
+		return logger
+	}
+	client.loggerLock.Lock()
+	defer client.loggerLock.Unlock()
 	if client.logger == nil {
-		client.SetLogger("", os.Stdout, "")
+		client.logger = newLogger("", os.Stdout, "")
 	}
-	return client.logger.lastLogMsg
+	return client.logger
 }
 
+// 最近一条日志，并发请求时可能是其他请求的日志，需要获取指定请求的日志时使用 WithRequestLogHook
+func (client *Client) GetLoggerMsg() string {
+	logger := client.getOrCreateLogger()
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+	return logger.lastLogMsg
+}
+
+// 设置日志，level 是输出的最低级别：debug、info、warn、error
 func (client *Client) SetLogger(level string, out io.Writer, template string) {
+	logger := newLogger(level, out, template)
+	client.loggerLock.Lock()
+	defer client.loggerLock.Unlock()
+	client.logger = logger
+}
+
+func newLogger(level string, out io.Writer, template string) *Logger {
 	if level == "" {
 		level = "info"
 	}
-
-	log := log.New(out, "["+strings.ToUpper(level)+"]", log.Lshortfile)
 	if template == "" {
 		template = defaultLoggerTemplate
 	}
 
-	client.logger = &Logger{
-		Logger:         log,
+	loggers := make(map[LogLevel]*log.Logger)
+	for _, l := range []LogLevel{LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError} {
+		loggers[l] = log.New(out, "["+strings.ToUpper(l.String())+"]", log.Lshortfile)
+	}
+	return &Logger{
+		Logger:         log.New(out, "["+strings.ToUpper(level)+"]", log.Lshortfile),
+		level:          ParseLogLevel(level),
+		loggers:        loggers,
 		formatTemplate: template,
 		isOpen:         true,
 	}
 }
 
 func (client *Client) OpenLogger() {
-	if client.logger == nil {
-		client.SetLogger("", os.Stdout, "")
-	}
-	client.logger.isOpen = true
+	logger := client.getOrCreateLogger()
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+	logger.isOpen = true
 }
 
 func (client *Client) CloseLogger() {
-	if client.logger != nil {
-		client.logger.isOpen = false
+	if logger := client.GetLogger(); logger != nil {
+		logger.lock.Lock()
+		defer logger.lock.Unlock()
+		logger.isOpen = false
 	}
 }
 
 func (client *Client) SetTemplate(template string) {
-	if client.logger == nil {
-		client.SetLogger("", os.Stdout, "")
-	}
-	client.logger.formatTemplate = template
+	logger := client.getOrCreateLogger()
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+	logger.formatTemplate = template
 }
 
 func (client *Client) GetTemplate() string {
-	if client.logger == nil {
-		client.SetLogger("", os.Stdout, "")
+	logger := client.getOrCreateLogger()
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+	return logger.formatTemplate
+}
+
+// 设置输出的最低日志级别
+func (client *Client) SetLogLevel(level LogLevel) {
+	logger := client.getOrCreateLogger()
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+	logger.level = level
+}
+
+func formatLogMsg(template string, fieldMap map[string]string) string {
+	logMsg := template
+	for key, value := range fieldMap {
+		logMsg = strings.Replace(logMsg, key, value, -1)
 	}
-	return client.logger.formatTemplate
+	return logMsg
 }
 
-func (client *Client) printLog(fieldMap map[string]string, err error) {
+func (client *Client) printLog(request Request, level LogLevel, fieldMap map[string]string, err error) {
 	if err != nil {
 		fieldMap["{error}"] = err.Error()
 	}
 	fieldMap["{time}"] = time.Now().Format("2006-01-02 15:04:05")
 	fieldMap["{ts}"] = timeISO8601()
-	if client.logger != nil {
-		logMsg := client.logger.formatTemplate
-		for key, value := range fieldMap {
-			logMsg = strings.Replace(logMsg, key, value, -1)
-		}
-		client.logger.lastLogMsg = logMsg
-		if client.logger.isOpen {
-			client.logger.Output(2, logMsg)
+
+	hook := requestLogHook(request)
+	logger := client.GetLogger()
+	if logger == nil {
+		if hook != nil {
+			hook(level, formatLogMsg(defaultLoggerTemplate, fieldMap))
 		}
+		return
 	}
-}
 
-type Debug func(format string, v ...any)
+	logger.lock.Lock()
+	logMsg := formatLogMsg(logger.formatTemplate, fieldMap)
+	logger.lastLogMsg = logMsg
+	output := logger.isOpen && level >= logger.level
+	logger.lock.Unlock()
 
-func getDebug(flag string) Debug {
-	enable := false
-
-	env := os.Getenv("DEBUG")
-	parts := strings.Split(env, ",")
-	for _, part := range parts {
-		if part == flag {
-			enable = true
-			break
-		}
+	if hook != nil {
+		hook(level, logMsg)
 	}
-
-	return func(format string, v ...any) {
-		if enable {
-			fmt.Println(fmt.Sprintf(format, v...))
-		}
+	if output {
+		logger.loggers[level].Output(2, logMsg)
 	}
 }
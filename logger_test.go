@@ -0,0 +1,67 @@
+package uim
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerLevel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAuthorization(false), WithBaseUrl(server.URL))
+	out := new(bytes.Buffer)
+	client.SetLogger("warn", out, "{uri} {code}")
+
+	assert.Nil(t, client.DoAction(NewBaseRequest(), &BaseResponse{}))
+	assert.Empty(t, out.String())
+
+	req := NewBaseRequest()
+	req.AddQueryParam("fail", "1")
+	assert.NotNil(t, client.DoAction(req, &BaseResponse{}))
+	assert.True(t, strings.HasPrefix(out.String(), "[ERROR]"), out.String())
+	assert.True(t, strings.Contains(out.String(), "/?fail=1 400"), out.String())
+}
+
+// 需要使用 -race 运行：go test -race -run TestConcurrentLogHook
+func TestConcurrentLogHook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAuthorization(false), WithBaseUrl(server.URL))
+	client.SetLogger("", new(bytes.Buffer), "{uri}")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := string(rune('a' + i))
+			var msgs []string
+			req := NewBaseRequest()
+			req.AddQueryParam("id", id)
+			err := client.DoAction(req, &BaseResponse{}, WithRequestLogHook(func(level LogLevel, msg string) {
+				assert.Equal(t, LogLevelInfo, level)
+				msgs = append(msgs, msg)
+			}))
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"/?id=" + id}, msgs)
+			_ = client.GetLoggerMsg()
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,138 @@
+package uim
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 脱敏后的替换值
+const RedactedValue = "[REDACTED]"
+
+// 需要脱敏的 header，不区分大小写
+var redactedHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+}
+
+// 需要脱敏的 JSON 字段，不区分大小写
+var redactedFields = map[string]bool{
+	"client_secret":    true,
+	"access_token":     true,
+	"mobile":           true,
+	"email":            true,
+	"private_metadata": true,
+}
+
+// 复制一份 header 并脱敏
+func redactHeaders(header http.Header) http.Header {
+	redacted := make(http.Header, len(header))
+	for key, values := range header {
+		if redactedHeaders[strings.ToLower(key)] {
+			redacted[key] = []string{RedactedValue}
+		} else {
+			redacted[key] = values
+		}
+	}
+	return redacted
+}
+
+// 脱敏 JSON 内容，不是 JSON 时原样返回
+func redactJSON(content []byte) []byte {
+	var value any
+	if err := json.Unmarshal(content, &value); err != nil {
+		return content
+	}
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return content
+	}
+	return redacted
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if redactedFields[strings.ToLower(key)] {
+				v[key] = RedactedValue
+			} else {
+				v[key] = redactValue(field)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+func (client *Client) logEnabled(ctx context.Context, level slog.Level) bool {
+	return client.slog != nil && client.slog.Enabled(ctx, level)
+}
+
+func (client *Client) logAttrs(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	if client.logEnabled(ctx, level) {
+		client.slog.LogAttrs(ctx, level, msg, attrs...)
+	}
+}
+
+// 记录一次调用 UIM 的请求，失败时使用 warn 级别，debug 级别时附带脱敏后的 header 和 body
+func (client *Client) logRequest(ctx context.Context, eventType string, retry int, request Request, httpRequest *http.Request, httpResponse *http.Response, cost time.Duration, err error) {
+	level := slog.LevelInfo
+	if err != nil || isServerError(httpResponse) {
+		level = slog.LevelWarn
+	}
+	if !client.logEnabled(ctx, level) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("event_type", eventType),
+		slog.String("method", httpRequest.Method),
+		slog.String("url", httpRequest.URL.String()),
+		slog.Int("retry", retry),
+		slog.Duration("cost", cost),
+	}
+	if httpResponse != nil {
+		attrs = append(attrs, slog.Int("status", httpResponse.StatusCode))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	if client.logEnabled(ctx, slog.LevelDebug) {
+		attrs = append(attrs,
+			slog.Any("req_headers", redactHeaders(httpRequest.Header)),
+			slog.String("req_body", string(redactJSON(request.GetContent()))),
+		)
+	}
+	client.logAttrs(ctx, level, "uim request", attrs...)
+}
+
+// 记录一次收到的事件，debug 级别时附带脱敏后的事件内容
+func (client *Client) logEvent(ctx context.Context, r *http.Request, body []byte, eventType, result string, cost time.Duration) {
+	level := slog.LevelInfo
+	if result != InboundEventResultOK {
+		level = slog.LevelWarn
+	}
+	if !client.logEnabled(ctx, level) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("event_type", eventType),
+		slog.String("result", result),
+		slog.Duration("cost", cost),
+	}
+	if client.logEnabled(ctx, slog.LevelDebug) {
+		attrs = append(attrs,
+			slog.Any("req_headers", redactHeaders(r.Header)),
+			slog.String("req_body", string(redactJSON(body))),
+		)
+	}
+	client.logAttrs(ctx, level, "uim event", attrs...)
+}
@@ -0,0 +1,47 @@
+package uim
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactJSON(t *testing.T) {
+	redacted := string(redactJSON([]byte(`{"client_secret":"s","data":{"Mobile":"138","nickname":"n","members":[{"email":"a@b.c"}],"private_metadata":{"k":"v"}}}`)))
+	assert.Equal(t, `{"client_secret":"[REDACTED]","data":{"Mobile":"[REDACTED]","members":[{"email":"[REDACTED]"}],"nickname":"n","private_metadata":"[REDACTED]"}}`, redacted)
+	assert.Equal(t, "not json", string(redactJSON([]byte("not json"))))
+}
+
+func TestStructuredLogRedaction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	out := new(bytes.Buffer)
+	client := NewClient(
+		WithAuthorization(false),
+		WithEventSource("provider.source/test/test"),
+		WithBaseUrl(server.URL),
+		WithLogHandler(slog.NewJSONHandler(out, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	)
+	client.SetLogger("", new(bytes.Buffer), "{req_headers}")
+
+	email := "someone@example.com"
+	err := client.SendEvent(ProviderEventNewContact, &Contact{IMUser: IMUser{UserId: "u1", Email: email}, Account: "a1"}, func(r Request) {
+		r.AddHeaderParam("Authorization", "Bearer secret-token")
+	})
+	assert.Nil(t, err)
+
+	logs := out.String()
+	assert.True(t, strings.Contains(logs, `"msg":"uim request"`), logs)
+	assert.True(t, strings.Contains(logs, ProviderEventNewContact), logs)
+	assert.False(t, strings.Contains(logs, "secret-token"), logs)
+	assert.False(t, strings.Contains(logs, email), logs)
+	assert.False(t, strings.Contains(client.GetLoggerMsg(), "secret-token"), client.GetLoggerMsg())
+}
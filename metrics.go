@@ -0,0 +1,52 @@
+package uim
+
+import (
+	"strconv"
+	"time"
+)
+
+// 指标收集，可以通过 WithMetrics 设置，metrics 包提供了 Prometheus 的实现
+type Metrics interface {
+	ObserveOutboundEvent(eventType, status string, retries int, latency time.Duration) // 发送给 UIM 的事件，status 是 HTTP 状态码或错误码
+	ObserveInboundEvent(eventType, result string, latency time.Duration)               // 收到的事件，result 是 ok 或错误码
+	ObserveTokenRefresh(success bool)                                                  // 获取 access token
+	SetAsyncQueueDepth(depth int)                                                      // 异步任务队列长度
+	SetCircuitState(state CircuitState)                                                // 熔断器状态
+}
+
+// 收到的事件处理成功
+const InboundEventResultOK = "ok"
+
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveOutboundEvent(string, string, int, time.Duration) {}
+func (noopMetrics) ObserveInboundEvent(string, string, time.Duration)       {}
+func (noopMetrics) ObserveTokenRefresh(bool)                                {}
+func (noopMetrics) SetAsyncQueueDepth(int)                                  {}
+func (noopMetrics) SetCircuitState(CircuitState)                            {}
+
+// 发送事件的结果状态
+func outboundStatus(response Response, err error) string {
+	if err != nil {
+		if e, ok := err.(Error); ok {
+			return e.ErrorCode()
+		}
+		return DefaultClientErrorCode
+	}
+	return strconv.Itoa(response.GetHttpStatus())
+}
+
+// 处理事件的结果
+func inboundResult(err error) string {
+	if err == nil {
+		return InboundEventResultOK
+	}
+	if e, ok := err.(Error); ok {
+		return e.ErrorCode()
+	}
+	return DefaultServerErrorCode
+}
+
+func (client *Client) observeAsyncQueue() {
+	client.metrics.SetAsyncQueueDepth(len(client.asyncTaskQueue))
+}
@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	uim "github.com/uimkit/provider-go"
+)
+
+// 基于 Prometheus 的指标收集，同时实现了 uim.Metrics 和 prometheus.Collector
+type Prometheus struct {
+	outboundEvents   *prometheus.CounterVec
+	outboundRetries  *prometheus.CounterVec
+	outboundDuration *prometheus.HistogramVec
+	inboundEvents    *prometheus.CounterVec
+	inboundDuration  *prometheus.HistogramVec
+	tokenRefreshes   *prometheus.CounterVec
+	asyncQueueDepth  prometheus.Gauge
+	circuitState     prometheus.Gauge
+}
+
+var _ uim.Metrics = (*Prometheus)(nil)
+var _ prometheus.Collector = (*Prometheus)(nil)
+
+// namespace 是指标名前缀，为空时使用 uim
+func NewPrometheus(namespace string) *Prometheus {
+	if namespace == "" {
+		namespace = "uim"
+	}
+	return &Prometheus{
+		outboundEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "outbound_events_total",
+			Help:      "Events and commands sent to UIM, by type and status.",
+		}, []string{"type", "status"}),
+		outboundRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "outbound_event_retries_total",
+			Help:      "Retries of events and commands sent to UIM, by type.",
+		}, []string{"type"}),
+		outboundDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "outbound_event_duration_seconds",
+			Help:      "Latency of events and commands sent to UIM, including retries.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"type"}),
+		inboundEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "inbound_events_total",
+			Help:      "Events and commands received by EventHandler, by type and result.",
+		}, []string{"type", "result"}),
+		inboundDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "inbound_event_handler_duration_seconds",
+			Help:      "Latency of event and command handlers.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"type"}),
+		tokenRefreshes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "token_refreshes_total",
+			Help:      "Access token refreshes, by result.",
+		}, []string{"result"}),
+		asyncQueueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "async_queue_depth",
+			Help:      "Tasks waiting in the async task queue.",
+		}),
+		circuitState: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "circuit_state",
+			Help:      "State of the circuit breaker for calls to UIM: 0 closed, 1 open, 2 half open.",
+		}),
+	}
+}
+
+func (m *Prometheus) ObserveOutboundEvent(eventType, status string, retries int, latency time.Duration) {
+	m.outboundEvents.WithLabelValues(eventType, status).Inc()
+	if retries > 0 {
+		m.outboundRetries.WithLabelValues(eventType).Add(float64(retries))
+	}
+	m.outboundDuration.WithLabelValues(eventType).Observe(latency.Seconds())
+}
+
+func (m *Prometheus) ObserveInboundEvent(eventType, result string, latency time.Duration) {
+	m.inboundEvents.WithLabelValues(eventType, result).Inc()
+	if result != uim.UnsupportedEventTypeErrorCode {
+		m.inboundDuration.WithLabelValues(eventType).Observe(latency.Seconds())
+	}
+}
+
+func (m *Prometheus) ObserveTokenRefresh(success bool) {
+	m.tokenRefreshes.WithLabelValues(strconv.FormatBool(success)).Inc()
+}
+
+func (m *Prometheus) SetAsyncQueueDepth(depth int) {
+	m.asyncQueueDepth.Set(float64(depth))
+}
+
+func (m *Prometheus) SetCircuitState(state uim.CircuitState) {
+	m.circuitState.Set(float64(state))
+}
+
+func (m *Prometheus) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.outboundEvents,
+		m.outboundRetries,
+		m.outboundDuration,
+		m.inboundEvents,
+		m.inboundDuration,
+		m.tokenRefreshes,
+		m.asyncQueueDepth,
+		m.circuitState,
+	}
+}
+
+func (m *Prometheus) Describe(ch chan<- *prometheus.Desc) {
+	for _, collector := range m.collectors() {
+		collector.Describe(ch)
+	}
+}
+
+func (m *Prometheus) Collect(ch chan<- prometheus.Metric) {
+	for _, collector := range m.collectors() {
+		collector.Collect(ch)
+	}
+}
+
+// 暴露指标的 http.Handler，使用独立的 Registry
+func (m *Prometheus) Handler() http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(m)
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	uim "github.com/uimkit/provider-go"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	metrics := NewPrometheus("")
+	client := uim.NewClient(
+		uim.WithAuthorization(false),
+		uim.WithEventSource("provider.source/test/test"),
+		uim.WithBaseUrl(server.URL),
+		uim.WithCircuitBreaker(uim.CircuitSettings{}),
+		uim.WithMetrics(metrics),
+	)
+	err := client.SendEvent(uim.ProviderEventNewMessage, &uim.Message{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u1", Type: uim.MessageTypeText, Text: "hello"})
+	assert.Nil(t, err)
+
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := ioutil.ReadAll(recorder.Body)
+	output := string(body)
+	assert.True(t, strings.Contains(output, `uim_outbound_events_total{status="200",type="`+uim.ProviderEventNewMessage+`"} 1`), output)
+	assert.True(t, strings.Contains(output, "uim_outbound_event_duration_seconds_count"), output)
+	assert.True(t, strings.Contains(output, "uim_circuit_state 0"), output)
+}
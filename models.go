@@ -187,6 +187,7 @@ const (
 	MessageTypeFile        MessageType = "file"        // 文件消息
 	MessageTypeLink        MessageType = "link"        // 链接消息
 	MessageTypeLocation    MessageType = "location"    // 位置消息
+	MessageTypeChatRecord  MessageType = "chat_record" // 聊天记录消息，合并转发的多条消息
 )
 
 type ImageInfo struct {
@@ -244,6 +245,29 @@ type LinkAttachment struct {
 	Thumbnail   string `json:"thumbnail,omitempty"`   // 缩略图
 }
 
+// 聊天记录，合并转发的多条消息
+type ChatRecordAttachment struct {
+	Title    string               `json:"title,omitempty"` // 标题，如：群聊的聊天记录
+	Messages []*ChatRecordMessage `json:"messages"`        // 聊天记录中的消息，按发送时间升序
+}
+
+// 聊天记录中的一条消息
+type ChatRecordMessage struct {
+	UserId      string                 `json:"user_id,omitempty"`     // 发送人平台用户ID
+	Nickname    string                 `json:"nickname,omitempty"`    // 发送人昵称
+	Avatar      string                 `json:"avatar,omitempty"`      // 发送人头像
+	SentAt      *time.Time             `json:"sent_at,omitempty"`     // 发送时间
+	Type        MessageType            `json:"type,omitempty"`        // 消息类型
+	Text        string                 `json:"text,omitempty"`        // 文本消息
+	Image       *ImageAttachment       `json:"image,omitempty"`       // 图片消息、视频消息封面
+	Audio       *AudioAttachment       `json:"audio,omitempty"`       // 语音消息
+	Video       *VideoAttachment       `json:"video,omitempty"`       // 视频消息
+	MiniProgram *MiniProgramAttachment `json:"miniprogram,omitempty"` // 小程序消息
+	File        *FileAttachment        `json:"file,omitempty"`        // 文件消息
+	Link        *LinkAttachment        `json:"link,omitempty"`        // 链接消息
+	ChatRecord  *ChatRecordAttachment  `json:"chat_record,omitempty"` // 嵌套的聊天记录消息
+}
+
 // @所有人
 const MessageMentionedAll = "all"
 
@@ -254,6 +278,60 @@ type MessageMentionedUser struct {
 	EndAt   int    `json:"end_at"`            // 在文本中结束位置（不包含在内）
 }
 
+// 回复或引用的消息，只能引用同一个消息收发地址中的消息
+type MessageReference struct {
+	MessageId string      `json:"message_id,omitempty"` // 被引用消息的平台消息ID
+	Channel   string      `json:"channel,omitempty"`    // 被引用消息的收发地址，为空时和消息相同
+	UserId    string      `json:"user_id,omitempty"`    // 被引用消息的发送人平台用户ID
+	Type      MessageType `json:"type,omitempty"`       // 被引用消息的类型
+	Snippet   string      `json:"snippet,omitempty"`    // 被引用消息的摘要，如：文本的前几个字、[图片]
+}
+
+// 消息的投递状态
+type MessageStatus string
+
+const (
+	MessageStatusSending   MessageStatus = "sending"   // 发送中
+	MessageStatusSent      MessageStatus = "sent"      // 已发送到平台
+	MessageStatusDelivered MessageStatus = "delivered" // 已送达
+	MessageStatusRead      MessageStatus = "read"      // 已读
+	MessageStatusFailed    MessageStatus = "failed"    // 发送失败
+)
+
+// 消息接收人的回执
+type MessageReceipt struct {
+	UserId    string        `json:"user_id,omitempty"`    // 接收人的平台用户ID
+	Status    MessageStatus `json:"status,omitempty"`     // 接收人的状态，如：送达、已读
+	UpdatedAt *time.Time    `json:"updated_at,omitempty"` // 状态变更时间
+}
+
+// 消息的投递状态变更
+type MessageStatusChange struct {
+	MessageId    string            `json:"message_id,omitempty"`    // 平台消息ID
+	Channel      string            `json:"channel,omitempty"`       // 消息收发地址
+	Account      string            `json:"account,omitempty"`       // 归属账号的平台用户ID
+	Status       MessageStatus     `json:"status,omitempty"`        // 消息的状态，群消息为所有接收人中最早的状态
+	FailedReason string            `json:"failed_reason,omitempty"` // 发送失败的原因
+	Receipts     []*MessageReceipt `json:"receipts,omitempty"`      // 各接收人的回执，只包含本次变更的接收人
+}
+
+// 消息的表情回应
+type Reaction struct {
+	MessageId string     `json:"message_id,omitempty"` // 平台消息ID
+	Channel   string     `json:"channel,omitempty"`    // 消息收发地址
+	Account   string     `json:"account,omitempty"`    // 归属账号的平台用户ID
+	UserId    string     `json:"user_id,omitempty"`    // 回应人的平台用户ID
+	Emoji     string     `json:"emoji,omitempty"`      // 表情，如：👍，或平台的表情代码
+	CreatedAt *time.Time `json:"created_at,omitempty"` // 回应时间
+}
+
+// 消息的一种表情回应的汇总
+type ReactionCount struct {
+	Emoji   string `json:"emoji"`             // 表情
+	Count   int    `json:"count"`             // 回应的人数
+	Reacted bool   `json:"reacted,omitempty"` // 账号自己是否回应了该表情
+}
+
 // 消息
 type Message struct {
 	MessageId       string                 `json:"message_id,omitempty"`       // 平台消息ID
@@ -268,9 +346,14 @@ type Message struct {
 	MiniProgram     *MiniProgramAttachment `json:"miniprogram,omitempty"`      // 小程序消息
 	File            *FileAttachment        `json:"file,omitempty"`             // 文件消息
 	Link            *LinkAttachment        `json:"link,omitempty"`             // 链接消息
+	ChatRecord      *ChatRecordAttachment  `json:"chat_record,omitempty"`      // 聊天记录消息
 	MentionedUsers  []string               `json:"mentioned_users"`            // @用户列表，是平台用户ID
+	Reactions       []*ReactionCount       `json:"reactions,omitempty"`        // 表情回应的汇总
+	Reference       *MessageReference      `json:"reference,omitempty"`        // 回复或引用的消息
 	SentAt          *time.Time             `json:"sent_at,omitempty"`          // 发送时间
 	Revoked         bool                   `json:"revoked,omitempty"`          // 是否撤回
+	Status          MessageStatus          `json:"status,omitempty"`           // 投递状态，账号发送的消息才有
+	FailedReason    string                 `json:"failed_reason,omitempty"`    // 发送失败的原因
 	Metadata        map[string]any         `json:"metadata,omitempty"`         // 公开元数据
 	PrivateMetadata map[string]any         `json:"private_metadata,omitempty"` // 私有元数据
 	State           string                 `json:"state,omitempty"`            // 发送消息时携带的业务自定义数据，发送后返回消息会透传给业务方
@@ -290,8 +373,10 @@ type SendMessageRequest struct {
 	MiniProgram      *MiniProgramAttachment  `json:"miniprogram,omitempty"`       // 小程序消息
 	File             *FileAttachment         `json:"file,omitempty"`              // 文件消息
 	Link             *LinkAttachment         `json:"link,omitempty"`              // 链接消息
+	ChatRecord       *ChatRecordAttachment   `json:"chat_record,omitempty"`       // 聊天记录消息
 	Seq              int                     `json:"seq,omitempty"`               // 序列号，在会话中唯一且有序增长，用于确保消息顺序
 	MentionedUsers   []*MessageMentionedUser `json:"mentioned_users"`             // @用户列表，是平台用户ID
+	Reference        *MessageReference       `json:"reference,omitempty"`         // 回复或引用的消息
 }
 
 // 发送消息结果
@@ -302,12 +387,104 @@ type SendMessageResponse struct {
 
 // 消息变更
 type MessageUpdate struct {
-	MessageId       string         `json:"message_id,omitempty"`       // 平台消息ID
-	Channel         string         `json:"channel,omitempty"`          // 消息发送地址
-	Account         string         `json:"account,omitempty"`          // 归属账号的平台用户ID
-	Revoked         *bool          `json:"revoked,omitempty"`          // 是否撤回
-	Metadata        map[string]any `json:"metadata,omitempty"`         // 公开元数据
-	PrivateMetadata map[string]any `json:"private_metadata,omitempty"` // 私有元数据
+	MessageId       string           `json:"message_id,omitempty"`       // 平台消息ID
+	Channel         string           `json:"channel,omitempty"`          // 消息发送地址
+	Account         string           `json:"account,omitempty"`          // 归属账号的平台用户ID
+	Revoked         *bool            `json:"revoked,omitempty"`          // 是否撤回
+	Text            *string          `json:"text,omitempty"`             // 编辑后的文本
+	EditHistory     []*MessageEdit   `json:"edit_history,omitempty"`     // 编辑历史，按编辑时间升序，包含本次编辑
+	Reactions       []*ReactionCount `json:"reactions,omitempty"`        // 表情回应的汇总
+	Metadata        map[string]any   `json:"metadata,omitempty"`         // 公开元数据
+	PrivateMetadata map[string]any   `json:"private_metadata,omitempty"` // 私有元数据
+}
+
+// 消息的一次编辑
+type MessageEdit struct {
+	Text     string     `json:"text"`                // 编辑前的文本
+	EditedAt *time.Time `json:"edited_at,omitempty"` // 编辑时间
+}
+
+// 撤回消息
+type RevokeMessageRequest struct {
+	Account   string `json:"account,omitempty"`    // 归属账号的平台用户ID
+	Channel   string `json:"channel,omitempty"`    // 消息收发地址
+	MessageId string `json:"message_id,omitempty"` // 平台消息ID
+}
+
+// 撤回消息返回
+type RevokeMessageResponse struct {
+	BaseResponse
+	Message
+}
+
+// 编辑消息，只能编辑文本消息
+type EditMessageRequest struct {
+	Account        string                  `json:"account,omitempty"`    // 归属账号的平台用户ID
+	Channel        string                  `json:"channel,omitempty"`    // 消息收发地址
+	MessageId      string                  `json:"message_id,omitempty"` // 平台消息ID
+	Text           string                  `json:"text,omitempty"`       // 编辑后的文本
+	MentionedUsers []*MessageMentionedUser `json:"mentioned_users"`      // @用户列表，是平台用户ID
+}
+
+// 编辑消息返回
+type EditMessageResponse struct {
+	BaseResponse
+	Message
+}
+
+// 转发消息
+type ForwardMessageRequest struct {
+	Account    string   `json:"account,omitempty"`    // 归属账号的平台用户ID
+	Channel    string   `json:"channel,omitempty"`    // 被转发消息的收发地址
+	MessageIds []string `json:"message_ids"`          // 被转发消息的平台消息ID，按转发顺序
+	ToChannel  string   `json:"to_channel,omitempty"` // 转发到的消息收发地址
+	Merge      bool     `json:"merge,omitempty"`      // 是否合并为一条聊天记录消息转发，否则逐条转发
+	Title      string   `json:"title,omitempty"`      // 合并转发时聊天记录的标题
+}
+
+// 转发消息返回
+type ForwardMessageResponse struct {
+	BaseResponse
+	Messages []*Message `json:"messages"` // 转发后的消息，合并转发时只有一条聊天记录消息
+}
+
+// 回应或取消回应消息的表情
+type ReactMessageRequest struct {
+	Account   string `json:"account,omitempty"`    // 归属账号的平台用户ID
+	Channel   string `json:"channel,omitempty"`    // 消息收发地址
+	MessageId string `json:"message_id,omitempty"` // 平台消息ID
+	Emoji     string `json:"emoji,omitempty"`      // 表情
+	Remove    bool   `json:"remove,omitempty"`     // 是否取消回应
+}
+
+// 回应消息的表情返回
+type ReactMessageResponse struct {
+	BaseResponse
+	Reactions []*ReactionCount `json:"reactions"` // 回应后消息的表情回应汇总
+}
+
+// 将会话标记为已读
+type MarkReadRequest struct {
+	Account   string `json:"account,omitempty"`    // 归属账号的平台用户ID
+	Channel   string `json:"channel,omitempty"`    // 消息收发地址
+	MessageId string `json:"message_id,omitempty"` // 已读到的平台消息ID，为空时会话中的所有消息都标记为已读
+}
+
+// 将会话标记为已读返回
+type MarkReadResponse struct {
+	BaseResponse
+}
+
+// 删除消息，只从账号的会话中删除，不影响对方
+type DeleteMessageRequest struct {
+	Account   string `json:"account,omitempty"`    // 归属账号的平台用户ID
+	Channel   string `json:"channel,omitempty"`    // 消息收发地址
+	MessageId string `json:"message_id,omitempty"` // 平台消息ID
+}
+
+// 删除消息返回
+type DeleteMessageResponse struct {
+	BaseResponse
 }
 
 // 查询消息地址的信息
@@ -585,3 +762,31 @@ type PublishMomentResponse struct {
 	BaseResponse
 	Moment
 }
+
+// Provider 支持的能力，由 Provider 通过 WithCapabilities 声明
+type Capabilities struct {
+	MessageTypes       []MessageType         `json:"message_types,omitempty"`        // 支持发送的消息类型
+	MaxAttachmentSizes map[MessageType]int64 `json:"max_attachment_sizes,omitempty"` // 各消息类型附件的最大字节数，未列出的类型不限制
+	Moment             bool                  `json:"moment"`                         // 是否支持动态
+	GroupManagement    bool                  `json:"group_management"`               // 是否支持群组管理，如：禁言
+}
+
+// 查询 Provider 支持的能力
+type GetCapabilitiesRequest struct{}
+
+// 查询 Provider 支持的能力返回
+type GetCapabilitiesResponse struct {
+	BaseResponse
+	Capabilities
+	EventTypes []string `json:"event_types"` // 注册了处理函数的事件和指令类型
+}
+
+// 验证连接和认证
+type PingRequest struct{}
+
+// 验证连接和认证返回
+type PingResponse struct {
+	BaseResponse
+	EventSource string `json:"event_source"` // Provider 的事件来源
+	Version     string `json:"version"`      // SDK 版本
+}
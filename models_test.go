@@ -0,0 +1,102 @@
+package uim
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 序列化后再反序列化，结果应和原始值相同
+func assertJSONRoundTrip[T any](t *testing.T, value *T, expected string) {
+	b, err := json.Marshal(value)
+	assert.Nil(t, err)
+	assert.JSONEq(t, expected, string(b))
+
+	decoded := new(T)
+	assert.Nil(t, json.Unmarshal(b, decoded))
+	assert.Equal(t, value, decoded)
+}
+
+func TestMessageReferenceJSON(t *testing.T) {
+	assertJSONRoundTrip(t, &Message{
+		MessageId:      "m2",
+		Channel:        "c1",
+		Account:        "a1",
+		UserId:         "u1",
+		Type:           MessageTypeText,
+		Text:           "收到",
+		MentionedUsers: []string{},
+		Reference:      &MessageReference{MessageId: "m1", UserId: "u2", Type: MessageTypeImage, Snippet: "[图片]"},
+	}, `{
+		"message_id": "m2",
+		"channel": "c1",
+		"account": "a1",
+		"user_id": "u1",
+		"type": "text",
+		"text": "收到",
+		"mentioned_users": [],
+		"reference": {"message_id": "m1", "user_id": "u2", "type": "image", "snippet": "[图片]"}
+	}`)
+}
+
+func TestChatRecordAttachmentJSON(t *testing.T) {
+	sentAt := time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC)
+	assertJSONRoundTrip(t, &ChatRecordAttachment{
+		Title: "群聊的聊天记录",
+		Messages: []*ChatRecordMessage{
+			{UserId: "u1", Nickname: "张三", SentAt: &sentAt, Type: MessageTypeText, Text: "hello"},
+			{UserId: "u2", Type: MessageTypeChatRecord, ChatRecord: &ChatRecordAttachment{
+				Messages: []*ChatRecordMessage{{UserId: "u3", Type: MessageTypeImage, Image: &ImageAttachment{Infos: []*ImageInfo{{URL: "https://image.url"}}}}},
+			}},
+		},
+	}, `{
+		"title": "群聊的聊天记录",
+		"messages": [
+			{"user_id": "u1", "nickname": "张三", "sent_at": "2023-01-02T15:04:05Z", "type": "text", "text": "hello"},
+			{"user_id": "u2", "type": "chat_record", "chat_record": {
+				"messages": [{"user_id": "u3", "type": "image", "image": {"infos": [{"url": "https://image.url"}]}}]
+			}}
+		]
+	}`)
+}
+
+func TestReactionJSON(t *testing.T) {
+	createdAt := time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC)
+	assertJSONRoundTrip(t, &Reaction{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u2", Emoji: "👍", CreatedAt: &createdAt}, `{
+		"message_id": "m1",
+		"channel": "c1",
+		"account": "a1",
+		"user_id": "u2",
+		"emoji": "👍",
+		"created_at": "2023-01-02T15:04:05Z"
+	}`)
+	assertJSONRoundTrip(t, &ReactMessageResponse{Reactions: []*ReactionCount{{Emoji: "👍", Count: 2, Reacted: true}, {Emoji: "❤️", Count: 1}}}, `{
+		"reactions": [{"emoji": "👍", "count": 2, "reacted": true}, {"emoji": "❤️", "count": 1}]
+	}`)
+}
+
+func TestMessageStatusChangeJSON(t *testing.T) {
+	readAt := time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC)
+	assertJSONRoundTrip(t, &MessageStatusChange{
+		MessageId: "m1",
+		Channel:   "c1",
+		Account:   "a1",
+		Status:    MessageStatusDelivered,
+		Receipts:  []*MessageReceipt{{UserId: "u2", Status: MessageStatusRead, UpdatedAt: &readAt}},
+	}, `{
+		"message_id": "m1",
+		"channel": "c1",
+		"account": "a1",
+		"status": "delivered",
+		"receipts": [{"user_id": "u2", "status": "read", "updated_at": "2023-01-02T15:04:05Z"}]
+	}`)
+	assertJSONRoundTrip(t, &MessageStatusChange{MessageId: "m1", Channel: "c1", Account: "a1", Status: MessageStatusFailed, FailedReason: "blocked"}, `{
+		"message_id": "m1",
+		"channel": "c1",
+		"account": "a1",
+		"status": "failed",
+		"failed_reason": "blocked"
+	}`)
+}
@@ -1,6 +1,8 @@
 package uim
 
 import (
+	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -15,6 +17,7 @@ type Options struct {
 	ServerAudience      string            `default:""`
 	TokenEndpoint       string            `default:"https://uim.cn.authok.cn/oauth/token"`
 	EnableAuthorization bool              `default:"true"`
+	EventAuthorization  bool              `default:"true"` // 收到事件时是否验证 token
 	EventSource         string            `default:""`
 	Scheme              string            `default:"HTTPS"`
 	Domain              string            `default:"api.uimkit.chat"`
@@ -28,6 +31,8 @@ type Options struct {
 	MaxRetryTime        int32             `default:"3"`
 	UserAgent           string            `default:""`
 	Debug               bool              `default:"false"`
+	DebugOutput         io.Writer         `default:""`     // 调试输出，默认为标准输出
+	DebugBodyLimit      int32             `default:"4096"` // 调试输出的 body 最大长度，0 表示不截断
 	HttpTransport       *http.Transport   `default:""`
 	Transport           http.RoundTripper `default:""`
 	EnableAsync         bool              `default:"false"`
@@ -35,6 +40,21 @@ type Options struct {
 	GoRoutinePoolSize   int32             `default:"5"`
 	ReadTimeout         time.Duration     `default:"300000000000"` // 300s
 	ConnectTimeout      time.Duration     `default:"10000000000"`  // 10s
+	Outbox              Outbox            `default:""`
+	OutboxMaxAttempts   int32             `default:"10"`
+	OutboxMinBackoff    time.Duration     `default:"1000000000"`   // 1s
+	OutboxMaxBackoff    time.Duration     `default:"300000000000"` // 5m
+	OrderedDelivery     bool              `default:"false"`
+	OrderingKey         string            `default:"channel"`
+	RateLimit           float64           `default:""`
+	RateBurst           int32             `default:"1"`
+	AccountRateLimit    float64           `default:""`
+	AccountRateBurst    int32             `default:"1"`
+	CircuitBreaker      *CircuitSettings  `default:""`
+	TracerProvider      TracerProvider    `default:""`
+	Metrics             Metrics           `default:""`
+	LogHandler          slog.Handler      `default:""`
+	Capabilities        *Capabilities     `default:""` // 回复 uim.get_capabilities 时声明的能力
 }
 
 func NewOptions() (options *Options) {
@@ -72,6 +92,13 @@ func WithAuthorization(enable bool) Option {
 	}
 }
 
+// 设置收到事件时是否验证 token，只应在本地调试时关闭
+func WithEventAuthorization(enable bool) Option {
+	return func(o *Options) {
+		o.EventAuthorization = enable
+	}
+}
+
 func WithEventSource(es string) Option {
 	return func(o *Options) {
 		o.EventSource = es
@@ -144,12 +171,21 @@ func WithUserAgent(userAgent string) Option {
 	}
 }
 
+// 开启调试，输出调用 UIM 和收到事件的请求、响应，header 中的认证信息会被脱敏
 func WithDebug(isDebug bool) Option {
 	return func(o *Options) {
 		o.Debug = isDebug
 	}
 }
 
+// 设置调试输出，bodyLimit 是 body 的最大长度，超过时截断，0 表示不截断
+func WithDebugOutput(out io.Writer, bodyLimit int32) Option {
+	return func(o *Options) {
+		o.DebugOutput = out
+		o.DebugBodyLimit = bodyLimit
+	}
+}
+
 func WithTimeout(readTimeout, connectTimeout time.Duration) Option {
 	return func(o *Options) {
 		o.ReadTimeout = readTimeout
@@ -176,3 +212,82 @@ func WithAsync(enableAsync bool, maxTaskQueueSize, goRoutinePoolSize int32) Opti
 		o.GoRoutinePoolSize = goRoutinePoolSize
 	}
 }
+
+// 设置发件箱，事件发送前先写入发件箱，由后台按会话顺序投递，失败时退避重试
+func WithOutbox(outbox Outbox) Option {
+	return func(o *Options) {
+		o.Outbox = outbox
+	}
+}
+
+// 设置发件箱的重试策略
+// maxAttempts 是最大投递次数，超过后事件进入死信
+// minBackoff、maxBackoff 是指数退避的最小、最大等待时间
+func WithOutboxRetry(maxAttempts int32, minBackoff, maxBackoff time.Duration) Option {
+	return func(o *Options) {
+		o.OutboxMaxAttempts = maxAttempts
+		o.OutboxMinBackoff = minBackoff
+		o.OutboxMaxBackoff = maxBackoff
+	}
+}
+
+// 设置有序发送，相同会话的事件串行发送，不同会话之间并行发送
+// key 是区分会话的字段，可以为 OrderingKeyChannel、OrderingKeyAccount
+// 异步发送时使用 SendEventAsync，事件在调用时排队
+func WithOrderedDelivery(enable bool, key string) Option {
+	return func(o *Options) {
+		o.OrderedDelivery = enable
+		o.OrderingKey = key
+	}
+}
+
+// 设置全局限流，rate 是每秒允许的请求数，burst 是允许的突发请求数
+func WithRateLimit(rate float64, burst int32) Option {
+	return func(o *Options) {
+		o.RateLimit = rate
+		o.RateBurst = burst
+	}
+}
+
+// 设置按账号限流，账号取自事件数据的 account 字段
+func WithAccountRateLimit(rate float64, burst int32) Option {
+	return func(o *Options) {
+		o.AccountRateLimit = rate
+		o.AccountRateBurst = burst
+	}
+}
+
+// 设置调用 UIM 的熔断器，熔断时请求直接返回 SDK.CircuitOpen 错误
+func WithCircuitBreaker(settings CircuitSettings) Option {
+	return func(o *Options) {
+		o.CircuitBreaker = &settings
+	}
+}
+
+// 设置 OpenTelemetry 的 TracerProvider，开启调用 UIM 和处理事件的分布式追踪
+func WithTracerProvider(tp TracerProvider) Option {
+	return func(o *Options) {
+		o.TracerProvider = tp
+	}
+}
+
+// 设置指标收集，记录事件收发、access token 获取、异步队列和熔断器状态
+func WithMetrics(metrics Metrics) Option {
+	return func(o *Options) {
+		o.Metrics = metrics
+	}
+}
+
+// 设置结构化日志，记录调用 UIM 的请求和收到的事件，header 中的认证信息和事件中的敏感字段会被脱敏
+func WithLogHandler(handler slog.Handler) Option {
+	return func(o *Options) {
+		o.LogHandler = handler
+	}
+}
+
+// 声明 Provider 支持的能力，UIM 通过 uim.get_capabilities 查询，支持的指令由注册的处理函数自动得到
+func WithCapabilities(capabilities Capabilities) Option {
+	return func(o *Options) {
+		o.Capabilities = &capabilities
+	}
+}
@@ -0,0 +1,169 @@
+package uim
+
+import (
+	"sync"
+	"time"
+)
+
+// 有序发送时用于区分会话的字段
+const (
+	OrderingKeyChannel = "channel" // 按消息收发地址排序
+	OrderingKeyAccount = "account" // 按账号排序
+)
+
+// 按 key 串行执行任务，不同 key 之间并行执行
+type orderedExecutor struct {
+	lock   sync.Mutex
+	queues map[string][]func()
+}
+
+func newOrderedExecutor() *orderedExecutor {
+	return &orderedExecutor{
+		queues: make(map[string][]func()),
+	}
+}
+
+// 按调用顺序执行相同 key 的任务，阻塞直到任务执行完成
+func (executor *orderedExecutor) Do(key string, task func() error) error {
+	done := make(chan error, 1)
+	executor.Submit(key, func() {
+		done <- task()
+	})
+	return <-done
+}
+
+// 任务在调用时排队，不等待执行，由每个 key 的 goroutine 按排队顺序执行
+func (executor *orderedExecutor) Submit(key string, task func()) {
+	executor.lock.Lock()
+	queue, running := executor.queues[key]
+	executor.queues[key] = append(queue, task)
+	executor.lock.Unlock()
+
+	if !running {
+		go executor.drain(key)
+	}
+}
+
+func (executor *orderedExecutor) drain(key string) {
+	for {
+		executor.lock.Lock()
+		queue := executor.queues[key]
+		if len(queue) == 0 {
+			delete(executor.queues, key)
+			executor.lock.Unlock()
+			return
+		}
+		task := queue[0]
+		executor.queues[key] = queue[1:]
+		executor.lock.Unlock()
+
+		task()
+	}
+}
+
+func (client *Client) orderingKey(data any) string {
+	if client.options.OrderingKey == OrderingKeyAccount {
+		return getStringField(data, "Account")
+	}
+	return getStringField(data, "Channel", "Account")
+}
+
+// 按序列号处理相同 key 的指令，序列号不连续时最多等待 gapTimeout。
+// 每个 key 以第一次收到的序列号为起点，小于期望序列号的指令（重复投递、跳过后才到达、早于起点）不等待，直接处理；
+// 空闲超过 gapTimeout 的 key 会被清理，之后收到的指令重新作为起点
+type Sequencer struct {
+	gapTimeout time.Duration
+	lock       sync.Mutex
+	keys       map[string]*sequence
+	lastSweep  time.Time
+}
+
+type sequence struct {
+	next     int                   // 期望处理的下一个序列号
+	waiting  map[int]chan struct{} // 等待前序指令处理完成的序列号
+	active   int                   // 已经 Acquire 还没有 Release 的指令数
+	lastUsed time.Time             // 最后一次 Acquire 或 Release 的时间
+}
+
+func NewSequencer(gapTimeout time.Duration) *Sequencer {
+	return &Sequencer{
+		gapTimeout: gapTimeout,
+		keys:       make(map[string]*sequence),
+	}
+}
+
+// 等待轮到序列号 seq 处理，seq 小于等于 0 时不排序
+func (sequencer *Sequencer) Acquire(key string, seq int) {
+	if seq <= 0 {
+		return
+	}
+	sequencer.lock.Lock()
+	now := time.Now()
+	sequencer.sweep(now)
+	s, ok := sequencer.keys[key]
+	if !ok {
+		// 第一次收到的指令作为起点
+		s = &sequence{next: seq, waiting: make(map[int]chan struct{})}
+		sequencer.keys[key] = s
+	}
+	s.active++
+	s.lastUsed = now
+	if seq <= s.next {
+		sequencer.lock.Unlock()
+		return
+	}
+	ready := make(chan struct{})
+	s.waiting[seq] = ready
+	sequencer.lock.Unlock()
+
+	timer := time.NewTimer(sequencer.gapTimeout)
+	defer timer.Stop()
+	select {
+	case <-ready:
+	case <-timer.C:
+		sequencer.lock.Lock()
+		if _, waiting := s.waiting[seq]; waiting {
+			// 前序指令超时未到达，跳过缺失的序列号
+			delete(s.waiting, seq)
+			if seq > s.next {
+				s.next = seq
+			}
+		}
+		sequencer.lock.Unlock()
+	}
+}
+
+// 序列号 seq 处理完成，唤醒下一个序列号
+func (sequencer *Sequencer) Release(key string, seq int) {
+	if seq <= 0 {
+		return
+	}
+	sequencer.lock.Lock()
+	defer sequencer.lock.Unlock()
+	s, ok := sequencer.keys[key]
+	if !ok {
+		return
+	}
+	s.active--
+	s.lastUsed = time.Now()
+	if seq >= s.next {
+		s.next = seq + 1
+	}
+	if ready, ok := s.waiting[s.next]; ok {
+		delete(s.waiting, s.next)
+		close(ready)
+	}
+}
+
+// 清理空闲超过 gapTimeout 的 key，最多每 gapTimeout 清理一次，调用方需要持有锁
+func (sequencer *Sequencer) sweep(now time.Time) {
+	if now.Sub(sequencer.lastSweep) < sequencer.gapTimeout {
+		return
+	}
+	sequencer.lastSweep = now
+	for key, s := range sequencer.keys {
+		if s.active <= 0 && now.Sub(s.lastUsed) >= sequencer.gapTimeout {
+			delete(sequencer.keys, key)
+		}
+	}
+}
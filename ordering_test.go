@@ -0,0 +1,199 @@
+package uim
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderedExecutor(t *testing.T) {
+	executor := newOrderedExecutor()
+	var lock sync.Mutex
+	results := make(map[string][]int)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		key := []string{"a", "b"}[i%2]
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = executor.Do(key, func() error {
+				time.Sleep(5 * time.Millisecond)
+				lock.Lock()
+				results[key] = append(results[key], i)
+				lock.Unlock()
+				return nil
+			})
+		}(i)
+		// 等待任务入队，保证调用顺序
+		time.Sleep(time.Millisecond)
+	}
+	wg.Wait()
+	assert.Equal(t, []int{0, 2, 4, 6, 8, 10, 12, 14, 16, 18}, results["a"])
+	assert.Equal(t, []int{1, 3, 5, 7, 9, 11, 13, 15, 17, 19}, results["b"])
+}
+
+// 收到的消息事件
+func decodeMessageEvent(r *http.Request) *Message {
+	event := struct {
+		Data *Message `json:"data"`
+	}{}
+	_ = json.NewDecoder(r.Body).Decode(&event)
+	return event.Data
+}
+
+func newOrderedClient(baseUrl string, poolSize int32) *Client {
+	return NewClient(
+		WithAuthorization(false),
+		WithEventSource("provider.source/test/test"),
+		WithBaseUrl(baseUrl),
+		WithAsync(true, 100, poolSize),
+		WithOrderedDelivery(true, OrderingKeyChannel),
+	)
+}
+
+func TestSendEventAsyncOrdered(t *testing.T) {
+	var lock sync.Mutex
+	received := make(map[string][]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		message := decodeMessageEvent(r)
+		// 打乱处理时间，没有排序时后发送的消息可能先到达
+		time.Sleep(time.Duration(rand.Intn(5)) * time.Millisecond)
+		lock.Lock()
+		received[message.Channel] = append(received[message.Channel], message.MessageId)
+		lock.Unlock()
+	}))
+	defer server.Close()
+
+	client := newOrderedClient(server.URL, 4)
+	defer client.Shutdown()
+
+	var wg sync.WaitGroup
+	sent := make(map[string][]string)
+	for i := 0; i < 40; i++ {
+		channel := []string{"c1", "c2"}[i%2]
+		message := &Message{MessageId: fmt.Sprintf("m%d", i), Channel: channel, Account: "a1", UserId: "u1", Type: MessageTypeText, Text: "hello"}
+		sent[channel] = append(sent[channel], message.MessageId)
+		wg.Add(1)
+		err := client.SendEventAsync(ProviderEventNewMessage, message, func(err error) {
+			defer wg.Done()
+			assert.Nil(t, err)
+		})
+		assert.Nil(t, err)
+	}
+	wg.Wait()
+	assert.Equal(t, sent, received)
+}
+
+func TestSendEventAsyncBusyChannel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if decodeMessageEvent(r).Channel == "busy" {
+			<-release
+		}
+	}))
+	defer server.Close()
+
+	// 只有一个 worker，阻塞的会话不应影响其他会话
+	client := newOrderedClient(server.URL, 1)
+	defer client.Shutdown()
+
+	var wg sync.WaitGroup
+	send := func(channel string, callback func(error)) {
+		message := &Message{MessageId: "m1", Channel: channel, Account: "a1", UserId: "u1", Type: MessageTypeText, Text: "hello"}
+		assert.Nil(t, client.SendEventAsync(ProviderEventNewMessage, message, callback))
+	}
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		send("busy", func(err error) {
+			defer wg.Done()
+			assert.Nil(t, err)
+		})
+	}
+	done := make(chan error, 1)
+	send("idle", func(err error) {
+		done <- err
+	})
+	select {
+	case err := <-done:
+		assert.Nil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("idle channel is blocked by the busy channel")
+	}
+	close(release)
+	wg.Wait()
+}
+
+func TestSendEventAsyncNotEnabled(t *testing.T) {
+	client := NewClient(WithAuthorization(false), WithOrderedDelivery(true, OrderingKeyChannel))
+	message := &Message{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u1", Type: MessageTypeText, Text: "hello"}
+	err := client.SendEventAsync(ProviderEventNewMessage, message, nil)
+	assert.Equal(t, AsyncFunctionNotEnabledCode, err.(Error).ErrorCode())
+}
+
+func TestSequencer(t *testing.T) {
+	sequencer := NewSequencer(200 * time.Millisecond)
+	var lock sync.Mutex
+	order := make([]int, 0)
+	process := func(seq int, wg *sync.WaitGroup) {
+		defer wg.Done()
+		sequencer.Acquire("c1", seq)
+		lock.Lock()
+		order = append(order, seq)
+		lock.Unlock()
+		sequencer.Release("c1", seq)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	process(1, &wg)
+	for _, seq := range []int{4, 3, 2} {
+		wg.Add(1)
+		go process(seq, &wg)
+		time.Sleep(10 * time.Millisecond)
+	}
+	wg.Wait()
+	assert.Equal(t, []int{1, 2, 3, 4}, order)
+
+	// 缺失的序列号超时后跳过
+	start := time.Now()
+	wg.Add(1)
+	process(6, &wg)
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	assert.Equal(t, []int{1, 2, 3, 4, 6}, order)
+}
+
+func TestSequencerIdleKeys(t *testing.T) {
+	sequencer := NewSequencer(50 * time.Millisecond)
+	sequencer.Acquire("c1", 3)
+	sequencer.Release("c1", 3)
+
+	// 小于期望序列号的指令不等待
+	start := time.Now()
+	sequencer.Acquire("c1", 1)
+	sequencer.Release("c1", 1)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, 4, sequencer.keys["c1"].next)
+
+	// 处理中的 key 不会被清理
+	sequencer.Acquire("c2", 1)
+	time.Sleep(60 * time.Millisecond)
+	sequencer.Acquire("c3", 1)
+	sequencer.Release("c3", 1)
+	assert.NotContains(t, sequencer.keys, "c1")
+	assert.Contains(t, sequencer.keys, "c2")
+	sequencer.Release("c2", 1)
+
+	// 清理后重新以收到的序列号为起点
+	start = time.Now()
+	sequencer.Acquire("c1", 10)
+	sequencer.Release("c1", 10)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, 11, sequencer.keys["c1"].next)
+}
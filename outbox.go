@@ -0,0 +1,396 @@
+package uim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 发件箱事件状态
+type OutboxEntryState string
+
+const (
+	OutboxEntryStatePending OutboxEntryState = "pending" // 待投递
+	OutboxEntryStateFailed  OutboxEntryState = "failed"  // 投递失败，进入死信
+)
+
+// 发件箱中的事件
+type OutboxEntry struct {
+	ID            string           `json:"id"`                   // 事件ID
+	Seq           int64            `json:"seq"`                  // 写入顺序
+	Key           string           `json:"key,omitempty"`        // 会话标识，相同会话的事件按写入顺序投递
+	Type          string           `json:"type"`                 // 事件类型
+	Content       []byte           `json:"content"`              // 序列化后的 cloudevent
+	State         OutboxEntryState `json:"state"`                // 状态
+	Attempts      int32            `json:"attempts"`             // 已投递次数
+	LastError     string           `json:"last_error,omitempty"` // 最后一次投递的错误
+	CreatedAt     time.Time        `json:"created_at"`           // 写入时间
+	NextAttemptAt time.Time        `json:"next_attempt_at"`      // 下次投递时间
+}
+
+// 发件箱，在发送前持久化事件，UIM 不可用时由后台重新投递
+type Outbox interface {
+	Save(entry *OutboxEntry) error                       // 新增或更新事件
+	Delete(id string) error                              // 删除已投递的事件
+	List(state OutboxEntryState) ([]*OutboxEntry, error) // 按 Seq 升序列出指定状态的事件
+}
+
+// 基于文件目录的发件箱，每个事件保存为一个 json 文件
+type FileOutbox struct {
+	dir     string
+	lock    sync.Mutex
+	entries map[string]*OutboxEntry
+}
+
+func NewFileOutbox(dir string) (*FileOutbox, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	outbox := &FileOutbox{
+		dir:     dir,
+		entries: make(map[string]*OutboxEntry),
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		entry := &OutboxEntry{}
+		if err := json.Unmarshal(content, entry); err != nil {
+			return nil, err
+		}
+		outbox.entries[entry.ID] = entry
+	}
+	return outbox, nil
+}
+
+func (outbox *FileOutbox) path(id string) string {
+	return filepath.Join(outbox.dir, strings.ReplaceAll(id, string(filepath.Separator), "_")+".json")
+}
+
+func (outbox *FileOutbox) Save(entry *OutboxEntry) error {
+	outbox.lock.Lock()
+	defer outbox.lock.Unlock()
+
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	// 先写临时文件再重命名，避免进程退出时留下不完整的文件
+	tmp := outbox.path(entry.ID) + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, outbox.path(entry.ID)); err != nil {
+		return err
+	}
+	saved := *entry
+	outbox.entries[entry.ID] = &saved
+	return nil
+}
+
+func (outbox *FileOutbox) Delete(id string) error {
+	outbox.lock.Lock()
+	defer outbox.lock.Unlock()
+
+	if err := os.Remove(outbox.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	delete(outbox.entries, id)
+	return nil
+}
+
+func (outbox *FileOutbox) List(state OutboxEntryState) ([]*OutboxEntry, error) {
+	outbox.lock.Lock()
+	defer outbox.lock.Unlock()
+
+	entries := make([]*OutboxEntry, 0)
+	for _, entry := range outbox.entries {
+		if entry.State == state {
+			copied := *entry
+			entries = append(entries, &copied)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Seq < entries[j].Seq
+	})
+	return entries, nil
+}
+
+// 发件箱的后台投递
+type outboxDispatcher struct {
+	client  *Client
+	outbox  Outbox
+	lock    sync.Mutex
+	lastSeq int64
+	opts    map[string][]RequestOption // 请求选项无法持久化，只对当前进程写入的事件生效
+	notify  chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newOutboxDispatcher(client *Client, outbox Outbox) *outboxDispatcher {
+	dispatcher := &outboxDispatcher{
+		client: client,
+		outbox: outbox,
+		opts:   make(map[string][]RequestOption),
+		notify: make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go dispatcher.loop()
+	return dispatcher
+}
+
+func (dispatcher *outboxDispatcher) enqueue(id, key, eventType string, content []byte, opts []RequestOption) error {
+	dispatcher.lock.Lock()
+	now := time.Now()
+	seq := now.UnixNano()
+	if seq <= dispatcher.lastSeq {
+		seq = dispatcher.lastSeq + 1
+	}
+	dispatcher.lastSeq = seq
+	if len(opts) > 0 {
+		dispatcher.opts[id] = opts
+	}
+	dispatcher.lock.Unlock()
+
+	err := dispatcher.outbox.Save(&OutboxEntry{
+		ID:            id,
+		Seq:           seq,
+		Key:           key,
+		Type:          eventType,
+		Content:       content,
+		State:         OutboxEntryStatePending,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	})
+	if err != nil {
+		dispatcher.lock.Lock()
+		delete(dispatcher.opts, id)
+		dispatcher.lock.Unlock()
+		return NewClientError(OutboxWriteErrorCode, OutboxWriteErrorMessage, err)
+	}
+	dispatcher.wakeup()
+	return nil
+}
+
+func (dispatcher *outboxDispatcher) wakeup() {
+	select {
+	case dispatcher.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (dispatcher *outboxDispatcher) loop() {
+	defer close(dispatcher.done)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-dispatcher.stop:
+			return
+		case <-dispatcher.notify:
+		case <-timer.C:
+		}
+		wait := dispatcher.dispatch()
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+	}
+}
+
+func (dispatcher *outboxDispatcher) logError(err error) {
+	dispatcher.client.logAttrs(context.Background(), slog.LevelWarn, "uim outbox", slog.String("error", err.Error()))
+}
+
+// 投递所有到期的事件，返回距离下一次投递的等待时间
+func (dispatcher *outboxDispatcher) dispatch() time.Duration {
+	options := dispatcher.client.options
+	wait := options.OutboxMaxBackoff
+	entries, err := dispatcher.outbox.List(OutboxEntryStatePending)
+	if err != nil {
+		dispatcher.logError(err)
+		return options.OutboxMinBackoff
+	}
+
+	blocked := make(map[string]bool)
+	for _, entry := range entries {
+		select {
+		case <-dispatcher.stop:
+			return wait
+		default:
+		}
+
+		// 同一会话中前面的事件未投递成功时，后面的事件需要等待
+		if entry.Key != "" && blocked[entry.Key] {
+			continue
+		}
+		if delay := time.Until(entry.NextAttemptAt); delay > 0 {
+			if delay < wait {
+				wait = delay
+			}
+			blocked[entry.Key] = true
+			continue
+		}
+
+		err := dispatcher.deliver(entry)
+		if err == nil {
+			if err := dispatcher.outbox.Delete(entry.ID); err != nil {
+				dispatcher.logError(err)
+			}
+			dispatcher.forget(entry.ID)
+			continue
+		}
+
+		entry.Attempts++
+		entry.LastError = err.Error()
+		if isPermanentError(err) || entry.Attempts >= options.OutboxMaxAttempts {
+			entry.State = OutboxEntryStateFailed
+			dispatcher.forget(entry.ID)
+		} else {
+			backoff := outboxBackoff(options.OutboxMinBackoff, options.OutboxMaxBackoff, entry.Attempts)
+			if serverError, ok := err.(*ServerError); ok && serverError.RetryAfter() > backoff {
+				backoff = serverError.RetryAfter()
+			}
+			entry.NextAttemptAt = time.Now().Add(backoff)
+			if backoff < wait {
+				wait = backoff
+			}
+			blocked[entry.Key] = true
+		}
+		if err := dispatcher.outbox.Save(entry); err != nil {
+			dispatcher.logError(err)
+		}
+	}
+	return wait
+}
+
+// 投递一次事件，token 失效（401）时刷新 token 后立即重试一次
+func (dispatcher *outboxDispatcher) deliver(entry *OutboxEntry) error {
+	dispatcher.lock.Lock()
+	opts := append(make([]RequestOption, 0, len(dispatcher.opts[entry.ID])+1), dispatcher.opts[entry.ID]...)
+	dispatcher.lock.Unlock()
+	opts = append(opts, detachRequestContext)
+
+	send := func() error {
+		req := NewBaseRequest()
+		req.SetContent(entry.Content)
+		return dispatcher.client.DoAction(req, &BaseResponse{}, opts...)
+	}
+	err := send()
+	if serverError, ok := err.(*ServerError); ok && serverError.HttpStatus() == http.StatusUnauthorized && dispatcher.client.options.EnableAuthorization {
+		dispatcher.client.resetAccessToken()
+		err = send()
+	}
+	return err
+}
+
+// 后台投递时调用方的 context 可能已经取消或超时，只保留其中的值（如 trace）
+func detachRequestContext(req Request) {
+	if r, ok := req.(contextRequest); ok && r.GetContext() != nil {
+		r.SetContext(context.WithoutCancel(r.GetContext()))
+	}
+}
+
+func (dispatcher *outboxDispatcher) forget(id string) {
+	dispatcher.lock.Lock()
+	delete(dispatcher.opts, id)
+	dispatcher.lock.Unlock()
+}
+
+func (dispatcher *outboxDispatcher) close() {
+	dispatcher.once.Do(func() {
+		close(dispatcher.stop)
+	})
+	<-dispatcher.done
+}
+
+// 指数退避，第 n 次失败后等待 minBackoff * 2^(n-1)，不超过 maxBackoff
+func outboxBackoff(minBackoff, maxBackoff time.Duration, attempts int32) time.Duration {
+	backoff := minBackoff
+	for i := int32(1); i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
+// 服务端明确拒绝的事件（如数据格式错误）重试也不会成功，
+// 超时（408）、限流（429）和认证失败（401）等错误仍需重试
+func isPermanentError(err error) bool {
+	if serverError, ok := err.(*ServerError); ok {
+		switch serverError.HttpStatus() {
+		case http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity:
+			return true
+		}
+	}
+	return false
+}
+
+// 查询发件箱中待投递和投递失败的事件数量
+func (client *Client) OutboxStats() (pending, failed int, err error) {
+	if client.outbox == nil {
+		return 0, 0, NewClientError(OutboxNotEnabledErrorCode, OutboxNotEnabledErrorMessage, nil)
+	}
+	pendingEntries, err := client.outbox.outbox.List(OutboxEntryStatePending)
+	if err != nil {
+		return
+	}
+	failedEntries, err := client.outbox.outbox.List(OutboxEntryStateFailed)
+	if err != nil {
+		return
+	}
+	return len(pendingEntries), len(failedEntries), nil
+}
+
+// 列出投递失败的事件（死信）
+func (client *Client) DeadLetters() ([]*OutboxEntry, error) {
+	if client.outbox == nil {
+		return nil, NewClientError(OutboxNotEnabledErrorCode, OutboxNotEnabledErrorMessage, nil)
+	}
+	return client.outbox.outbox.List(OutboxEntryStateFailed)
+}
+
+// 重新投递死信
+func (client *Client) RedeliverDeadLetter(id string) error {
+	if client.outbox == nil {
+		return NewClientError(OutboxNotEnabledErrorCode, OutboxNotEnabledErrorMessage, nil)
+	}
+	entries, err := client.outbox.outbox.List(OutboxEntryStateFailed)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.ID == id {
+			entry.State = OutboxEntryStatePending
+			entry.Attempts = 0
+			entry.NextAttemptAt = time.Now()
+			if err := client.outbox.outbox.Save(entry); err != nil {
+				return err
+			}
+			client.outbox.wakeup()
+			return nil
+		}
+	}
+	return NewClientError(OutboxEntryNotFoundErrorCode, fmt.Sprintf(OutboxEntryNotFoundErrorMessage, id), nil)
+}
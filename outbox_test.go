@@ -0,0 +1,205 @@
+package uim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type outboxTestPayload struct {
+	Channel string `json:"channel"`
+	Text    string `json:"text"`
+}
+
+func TestOutboxRedelivery(t *testing.T) {
+	var lock sync.Mutex
+	failures := 2
+	received := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		body, _ := ioutil.ReadAll(r.Body)
+		event := cloudevents.NewEvent()
+		_ = json.Unmarshal(body, &event)
+		payload := &outboxTestPayload{}
+		_ = event.DataAs(payload)
+		if payload.Text == "invalid" {
+			writeError(w, NewServerError(InvalidEventDataErrorStatus, InvalidEventDataErrorCode, "invalid", nil))
+			return
+		}
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		received = append(received, payload.Text)
+	}))
+	defer server.Close()
+
+	outbox, err := NewFileOutbox(t.TempDir())
+	assert.Nil(t, err)
+	client := NewClient(
+		WithAuthorization(false),
+		WithEventSource("provider.source/test/test"),
+		WithBaseUrl(server.URL),
+		WithOutbox(outbox),
+		WithOutboxRetry(5, 10*time.Millisecond, 50*time.Millisecond),
+	)
+	defer client.Shutdown()
+
+	for _, text := range []string{"1", "2", "invalid", "3"} {
+		err = client.SendEvent(ProviderEventNewMessage, &Message{MessageId: text, Channel: "c1", Account: "a1", UserId: "u1", Type: MessageTypeText, Text: text})
+		assert.Nil(t, err)
+	}
+
+	assert.Eventually(t, func() bool {
+		pending, failed, err := client.OutboxStats()
+		return err == nil && pending == 0 && failed == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	lock.Lock()
+	assert.Equal(t, []string{"1", "2", "3"}, received)
+	lock.Unlock()
+
+	deadLetters, err := client.DeadLetters()
+	assert.Nil(t, err)
+	assert.Len(t, deadLetters, 1)
+	assert.Equal(t, int32(1), deadLetters[0].Attempts)
+	assert.Equal(t, "c1", deadLetters[0].Key)
+
+	// 重新打开发件箱时恢复未删除的事件
+	reopened, err := NewFileOutbox(outbox.dir)
+	assert.Nil(t, err)
+	failed, err := reopened.List(OutboxEntryStateFailed)
+	assert.Nil(t, err)
+	assert.Len(t, failed, 1)
+}
+
+func TestOutboxNotEnabled(t *testing.T) {
+	client := NewClient()
+	_, _, err := client.OutboxStats()
+	assert.Equal(t, OutboxNotEnabledErrorCode, err.(*ClientError).ErrorCode())
+}
+
+func TestOutboxDetachedContext(t *testing.T) {
+	var lock sync.Mutex
+	failures := 1
+	received := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		received++
+	}))
+	defer server.Close()
+
+	outbox, err := NewFileOutbox(t.TempDir())
+	assert.Nil(t, err)
+	client := NewClient(
+		WithAuthorization(false),
+		WithEventSource("provider.source/test/test"),
+		WithBaseUrl(server.URL),
+		WithOutbox(outbox),
+		WithOutboxRetry(5, 10*time.Millisecond, 50*time.Millisecond),
+	)
+	defer client.Shutdown()
+
+	// 调用方的 context 取消后，发件箱仍然继续投递
+	ctx, cancel := context.WithCancel(context.Background())
+	err = client.SendEvent(ProviderEventNewMessage, &Message{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u1", Type: MessageTypeText, Text: "1"}, WithRequestContext(ctx))
+	assert.Nil(t, err)
+	cancel()
+
+	assert.Eventually(t, func() bool {
+		pending, failed, err := client.OutboxStats()
+		return err == nil && pending == 0 && failed == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	lock.Lock()
+	assert.Equal(t, 1, received)
+	lock.Unlock()
+}
+
+func TestOutboxRetryableStatus(t *testing.T) {
+	var lock sync.Mutex
+	statuses := []int{http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusUnauthorized}
+	tokens := 0
+	received := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		if r.URL.Path == "/oauth/token" {
+			tokens++
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": fmt.Sprintf("token%d", tokens), "expires_in": 3600})
+			return
+		}
+		if len(statuses) > 0 {
+			status := statuses[0]
+			statuses = statuses[1:]
+			if status == http.StatusTooManyRequests {
+				writeError(w, NewRetryableServerError(status, TooManyRequestsErrorCode, "too many requests", time.Second, nil))
+			} else {
+				w.WriteHeader(status)
+			}
+			return
+		}
+		received = append(received, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	outbox, err := NewFileOutbox(t.TempDir())
+	assert.Nil(t, err)
+	client := NewClient(
+		WithClient("id", "secret", "uim"),
+		WithTokenEndpoint(server.URL+"/oauth/token"),
+		WithEventSource("provider.source/test/test"),
+		WithBaseUrl(server.URL),
+		WithOutbox(outbox),
+		WithOutboxRetry(5, 10*time.Millisecond, 50*time.Millisecond),
+	)
+	defer client.Shutdown()
+
+	start := time.Now()
+	err = client.SendEvent(ProviderEventNewMessage, &Message{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u1", Type: MessageTypeText, Text: "1"})
+	assert.Nil(t, err)
+
+	assert.Eventually(t, func() bool {
+		pending, failed, err := client.OutboxStats()
+		return err == nil && pending == 0 && failed == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// 429 之后按 Retry-After 等待，401 之后刷新 token 立即重试
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	lock.Lock()
+	assert.Equal(t, []string{"Bearer token2"}, received)
+	assert.Equal(t, 2, tokens)
+	lock.Unlock()
+}
+
+func TestIsPermanentError(t *testing.T) {
+	for status, permanent := range map[int]bool{
+		http.StatusBadRequest:          true,
+		http.StatusNotFound:            true,
+		http.StatusConflict:            true,
+		http.StatusUnprocessableEntity: true,
+		http.StatusUnauthorized:        false,
+		http.StatusRequestTimeout:      false,
+		http.StatusTooManyRequests:     false,
+		http.StatusServiceUnavailable:  false,
+	} {
+		assert.Equal(t, permanent, isPermanentError(NewServerError(status, DefaultServerErrorCode, "", nil)), status)
+	}
+	assert.False(t, isPermanentError(NewClientError(DefaultClientErrorCode, "", nil)))
+}
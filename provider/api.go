@@ -45,6 +45,21 @@ func (client *Client) MessageUpdated(message *uim.MessageUpdate, opts ...uim.Req
 	return client.SendEvent(uim.ProviderEventMessageUpdated, message, opts...)
 }
 
+// 收到消息的表情回应
+func (client *Client) NewMessageReaction(reaction *uim.Reaction, opts ...uim.RequestOption) error {
+	return client.SendEvent(uim.ProviderEventNewMessageReaction, reaction, opts...)
+}
+
+// 消息的表情回应被取消
+func (client *Client) MessageReactionDeleted(reaction *uim.Reaction, opts ...uim.RequestOption) error {
+	return client.SendEvent(uim.ProviderEventMessageReactionDeleted, reaction, opts...)
+}
+
+// 消息的投递状态变更
+func (client *Client) MessageStatusChanged(change *uim.MessageStatusChange, opts ...uim.RequestOption) error {
+	return client.SendEvent(uim.ProviderEventMessageStatusChanged, change, opts...)
+}
+
 // 新群组
 func (client *Client) NewGroup(group *uim.Group, opts ...uim.RequestOption) error {
 	return client.SendEvent(uim.ProviderEventNewGroup, group, opts...)
@@ -101,47 +116,89 @@ func (client *Client) GetMetafield(metafield *uim.GetMetafieldRequest, opts ...u
 type SendMessageHandler func(*cloudevents.Event, *uim.SendMessageRequest) (*uim.SendMessageResponse, error)
 
 func (client *Client) OnSendMessage(handler SendMessageHandler) {
-	client.OnEvent(uim.UIMCommandSendMessage, uim.CastCommandHandler(handler))
+	uim.HandleCommand(client.Client, uim.UIMCommandSendMessage, handler)
+}
+
+// 撤回消息
+type RevokeMessageHandler func(*cloudevents.Event, *uim.RevokeMessageRequest) (*uim.RevokeMessageResponse, error)
+
+func (client *Client) OnRevokeMessage(handler RevokeMessageHandler) {
+	uim.HandleCommand(client.Client, uim.UIMCommandRevokeMessage, handler)
+}
+
+// 编辑消息
+type EditMessageHandler func(*cloudevents.Event, *uim.EditMessageRequest) (*uim.EditMessageResponse, error)
+
+func (client *Client) OnEditMessage(handler EditMessageHandler) {
+	uim.HandleCommand(client.Client, uim.UIMCommandEditMessage, handler)
+}
+
+// 删除消息
+type DeleteMessageHandler func(*cloudevents.Event, *uim.DeleteMessageRequest) (*uim.DeleteMessageResponse, error)
+
+func (client *Client) OnDeleteMessage(handler DeleteMessageHandler) {
+	uim.HandleCommand(client.Client, uim.UIMCommandDeleteMessage, handler)
+}
+
+// 转发消息
+type ForwardMessageHandler func(*cloudevents.Event, *uim.ForwardMessageRequest) (*uim.ForwardMessageResponse, error)
+
+func (client *Client) OnForwardMessage(handler ForwardMessageHandler) {
+	uim.HandleCommand(client.Client, uim.UIMCommandForwardMessage, handler)
+}
+
+// 回应或取消回应消息的表情
+type ReactMessageHandler func(*cloudevents.Event, *uim.ReactMessageRequest) (*uim.ReactMessageResponse, error)
+
+func (client *Client) OnReactMessage(handler ReactMessageHandler) {
+	uim.HandleCommand(client.Client, uim.UIMCommandReactMessage, handler)
+}
+
+// 将会话标记为已读
+type MarkReadHandler func(*cloudevents.Event, *uim.MarkReadRequest) (*uim.MarkReadResponse, error)
+
+func (client *Client) OnMarkRead(handler MarkReadHandler) {
+	uim.HandleCommand(client.Client, uim.UIMCommandMarkRead, handler)
 }
 
 // 发布朋友圈
 type PublishMomentHandler func(*cloudevents.Event, *uim.PublishMomentRequest) (*uim.PublishMomentResponse, error)
 
 func (client *Client) OnPublishMoment(handler PublishMomentHandler) {
-	client.OnEvent(uim.UIMCommandPublishMoment, uim.CastCommandHandler(handler))
+	uim.HandleCommand(client.Client, uim.UIMCommandPublishMoment, handler)
 }
 
 // 获取动态列表
 type GetMomentListHandler func(*cloudevents.Event, *uim.GetMomentListRequest) (*uim.GetMomentListResponse, error)
 
 func (client *Client) OnGetMomentList(handler GetMomentListHandler) {
-	client.OnEvent(uim.UIMCommandGetMomentList, uim.CastCommandHandler(handler))
+	uim.HandleCommand(client.Client, uim.UIMCommandGetMomentList, handler)
 }
 
 // 添加好友
 type AddContactHandler func(*cloudevents.Event, *uim.AddContactRequest) (*uim.AddContactResponse, error)
 
 func (client *Client) OnAddContact(handler AddContactHandler) {
-	client.OnEvent(uim.UIMCommandAddContact, uim.CastCommandHandler(handler))
+	uim.HandleCommand(client.Client, uim.UIMCommandAddContact, handler)
 }
 
 // 通过好友申请
 type AcceptFriendApplyHandler func(*cloudevents.Event, *uim.AcceptFriendApplyRequest) (*uim.AcceptFriendApplyResponse, error)
 
 func (client *Client) OnAcceptFriendApply(handler AcceptFriendApplyHandler) {
-	client.OnEvent(uim.UIMCommandAcceptFriendApply, uim.CastCommandHandler(handler))
+	uim.HandleCommand(client.Client, uim.UIMCommandAcceptFriendApply, handler)
 }
 
 // 查询消息地址关联的信息
 type GetChannelInfoHandler func(*cloudevents.Event, *uim.GetChannelInfoRequest) (*uim.GetChannelInfoResponse, error)
 
 func (client *Client) OnGetChannelInfo(handler GetChannelInfoHandler) {
-	client.OnEvent(uim.UIMCommandGetChannelInfo, uim.CastCommandHandler(handler))
+	uim.HandleCommand(client.Client, uim.UIMCommandGetChannelInfo, handler)
 }
 
 // 设置群组禁言
 type SetGroupMuteHandler func(*cloudevents.Event, *uim.SetGroupMuteRequest) (*uim.SetGroupMuteResponse, error)
 
 func (client *Client) OnSetGroupMute(handler SetGroupMuteHandler) {
-	client.OnEvent(uim.UIMCommandSetGroupMute, uim.CastCommandHandler(handler))
+	uim.HandleCommand(client.Client, uim.UIMCommandSetGroupMute, handler)
 }
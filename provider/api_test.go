@@ -3,15 +3,18 @@ package provider
 import (
 	"encoding/json"
 	"fmt"
+	"net/http/httptest"
 	"os"
 	"strconv"
 	"testing"
 	"time"
 
+	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/hokaccha/go-prettyjson"
 	gonanoid "github.com/matoous/go-nanoid/v2"
 	"github.com/stretchr/testify/assert"
 	uim "github.com/uimkit/provider-go"
+	"github.com/uimkit/provider-go/server"
 )
 
 const defaultUserId = "wxid_SPdd_nkhEYnA_Yf5gN5sp"
@@ -389,7 +392,7 @@ func TestIMAccount(t *testing.T) {
 	client := newProviderClient()
 
 	err = client.NewAccount(&uim.IMAccount{})
-	assert.Equal(t, uim.InvalidEventDataErrorCode, err.(*uim.ServerError).ErrorCode())
+	assert.Equal(t, uim.InvalidEventDataErrorCode, err.(uim.Error).ErrorCode())
 
 	birthday := time.Now().Add(-365 * 10 * 24 * 3600 * time.Second)
 	userId, _ := gonanoid.New()
@@ -428,7 +431,7 @@ func TestIMAccount(t *testing.T) {
 	assert.Nil(t, err)
 
 	err = client.AccountUpdated(&uim.IMAccountUpdate{})
-	assert.Equal(t, uim.InvalidEventDataErrorCode, err.(*uim.ServerError).ErrorCode())
+	assert.Equal(t, uim.InvalidEventDataErrorCode, err.(uim.Error).ErrorCode())
 
 	err = client.AccountUpdated(&uim.IMAccountUpdate{
 		IMUserUpdate: uim.IMUserUpdate{
@@ -588,3 +591,68 @@ func TestJSON(t *testing.T) {
 	assert.Nil(t, err)
 	t.Logf("%+v", account)
 }
+
+// 创建发送事件给 UIM 的 SDK，事件由 server 的 EventHandler 处理
+func newEventClient(t *testing.T, s *server.Client) *Client {
+	ts := httptest.NewServer(s.EventHandler())
+	t.Cleanup(ts.Close)
+	return NewClient(
+		uim.WithAuthorization(false),
+		WithProvider("provider-go", "test"),
+		uim.WithBaseUrl(ts.URL),
+	)
+}
+
+func TestMessageReactionEvents(t *testing.T) {
+	s := server.NewClient(uim.WithEventAuthorization(false), server.WithServerName("test"))
+	var added, deleted []*uim.Reaction
+	s.OnNewMessageReaction(func(_ *cloudevents.Event, reaction *uim.Reaction) error {
+		added = append(added, reaction)
+		return nil
+	})
+	s.OnMessageReactionDeleted(func(_ *cloudevents.Event, reaction *uim.Reaction) error {
+		deleted = append(deleted, reaction)
+		return nil
+	})
+	client := newEventClient(t, s)
+
+	createdAt := time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC)
+	reaction := &uim.Reaction{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u2", Emoji: "👍", CreatedAt: &createdAt}
+	assert.Nil(t, client.NewMessageReaction(reaction))
+	assert.Equal(t, []*uim.Reaction{reaction}, added)
+
+	reaction = &uim.Reaction{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u2", Emoji: "👍"}
+	assert.Nil(t, client.MessageReactionDeleted(reaction))
+	assert.Equal(t, []*uim.Reaction{reaction}, deleted)
+
+	// 缺少表情时不会发送
+	err := client.NewMessageReaction(&uim.Reaction{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u2"})
+	assert.Equal(t, uim.InvalidEventDataErrorCode, err.(*uim.ClientError).ErrorCode())
+	assert.Len(t, added, 1)
+}
+
+func TestMessageStatusChangedEvent(t *testing.T) {
+	s := server.NewClient(uim.WithEventAuthorization(false), server.WithServerName("test"))
+	var changes []*uim.MessageStatusChange
+	s.OnMessageStatusChanged(func(_ *cloudevents.Event, change *uim.MessageStatusChange) error {
+		changes = append(changes, change)
+		return nil
+	})
+	client := newEventClient(t, s)
+
+	readAt := time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC)
+	change := &uim.MessageStatusChange{
+		MessageId: "m1",
+		Channel:   "c1",
+		Account:   "a1",
+		Status:    uim.MessageStatusDelivered,
+		Receipts:  []*uim.MessageReceipt{{UserId: "u2", Status: uim.MessageStatusRead, UpdatedAt: &readAt}},
+	}
+	assert.Nil(t, client.MessageStatusChanged(change))
+	assert.Equal(t, []*uim.MessageStatusChange{change}, changes)
+
+	// 发送失败时必须有失败原因
+	err := client.MessageStatusChanged(&uim.MessageStatusChange{MessageId: "m1", Channel: "c1", Account: "a1", Status: uim.MessageStatusFailed})
+	assert.Equal(t, uim.InvalidEventDataErrorCode, err.(*uim.ClientError).ErrorCode())
+	assert.Len(t, changes, 1)
+}
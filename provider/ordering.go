@@ -0,0 +1,18 @@
+package provider
+
+import (
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	uim "github.com/uimkit/provider-go"
+)
+
+// 按消息地址缓冲发送消息指令，按 Seq 顺序调用 handler，Seq 不连续时最多等待 gapTimeout
+func OrderedSendMessage(handler SendMessageHandler, gapTimeout time.Duration) SendMessageHandler {
+	sequencer := uim.NewSequencer(gapTimeout)
+	return func(event *cloudevents.Event, req *uim.SendMessageRequest) (*uim.SendMessageResponse, error) {
+		sequencer.Acquire(req.Channel, req.Seq)
+		defer sequencer.Release(req.Channel, req.Seq)
+		return handler(event, req)
+	}
+}
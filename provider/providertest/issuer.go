@@ -0,0 +1,11 @@
+package providertest
+
+import "github.com/uimkit/provider-go/uimtest/issuer"
+
+// 模拟 UIM 的认证服务，见 issuer.Issuer
+type Issuer = issuer.Issuer
+
+// 启动认证服务，使用完需要调用 Close
+func NewIssuer(audience string) *Issuer {
+	return issuer.New(audience)
+}
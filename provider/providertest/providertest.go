@@ -0,0 +1,242 @@
+// providertest 是 provider 实现的一致性测试，通过 EventHandler 向 provider 发送 UIM 的指令，检查认证、错误码、返回格式和幂等
+package providertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	uim "github.com/uimkit/provider-go"
+)
+
+// 测试发送的事件来源
+const EventSource = "uim.source/providertest"
+
+// 测试使用的数据
+type Fixtures struct {
+	Issuer          *Issuer        // 签发 token 的认证服务，provider 需要使用 Issuer.ServerOption 设置
+	Commands        map[string]any // provider 支持的指令和请求数据，数据为 nil 时使用默认数据，未列出的指令应返回 SDK.UnsupportedEventType
+	SkipIdempotency bool           // 跳过幂等检查
+}
+
+// UIM 调用 provider 的指令的默认请求数据，返回数据的类型从 uim.LookupEventType 获取
+var requests = map[string]any{
+	uim.UIMCommandGetChannelInfo: &uim.GetChannelInfoRequest{Channel: "providertest_channel"},
+	uim.UIMCommandSendMessage: &uim.SendMessageRequest{
+		Account:          "providertest_account",
+		Channel:          "providertest_channel",
+		ConversationType: uim.ConversationTypePrivate,
+		Type:             uim.MessageTypeText,
+		Text:             "hello",
+	},
+	uim.UIMCommandAddContact:        &uim.AddContactRequest{UserId: "providertest_account", Contact: "providertest_contact", HelloMessage: "hello"},
+	uim.UIMCommandAcceptFriendApply: &uim.AcceptFriendApplyRequest{ApplyId: "providertest_apply", UserId: "providertest_account"},
+	uim.UIMCommandGetMomentList:     &uim.GetMomentListRequest{CursorQuery: uim.CursorQuery{Limit: 10}, Account: "providertest_account"},
+	uim.UIMCommandSetGroupMute:      &uim.SetGroupMuteRequest{UserId: "providertest_account", GroupId: "providertest_group", Mute: true},
+	uim.UIMCommandPublishMoment:     &uim.PublishMomentRequest{Account: "providertest_account", Type: uim.MomentTypeText, Text: "hello", Privacy: uim.MomentPrivacyPublic},
+	uim.UIMCommandRevokeMessage:     &uim.RevokeMessageRequest{Account: "providertest_account", Channel: "providertest_channel", MessageId: "providertest_message"},
+	uim.UIMCommandEditMessage:       &uim.EditMessageRequest{Account: "providertest_account", Channel: "providertest_channel", MessageId: "providertest_message", Text: "hello"},
+	uim.UIMCommandDeleteMessage:     &uim.DeleteMessageRequest{Account: "providertest_account", Channel: "providertest_channel", MessageId: "providertest_message"},
+	uim.UIMCommandForwardMessage: &uim.ForwardMessageRequest{
+		Account:    "providertest_account",
+		Channel:    "providertest_channel",
+		MessageIds: []string{"providertest_message"},
+		ToChannel:  "providertest_to_channel",
+	},
+	uim.UIMCommandReactMessage:    &uim.ReactMessageRequest{Account: "providertest_account", Channel: "providertest_channel", MessageId: "providertest_message", Emoji: "👍"},
+	uim.UIMCommandMarkRead:        &uim.MarkReadRequest{Account: "providertest_account", Channel: "providertest_channel", MessageId: "providertest_message"},
+	uim.UIMCommandGetCapabilities: &uim.GetCapabilitiesRequest{},
+	uim.UIMCommandPing:            &uim.PingRequest{},
+}
+
+// 错误返回
+type errorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// 运行一致性测试，handler 一般为 provider 的 EventHandler()
+func Run(t *testing.T, handler http.Handler, fixtures Fixtures) {
+	t.Helper()
+	if fixtures.Issuer == nil {
+		t.Fatal("providertest: Fixtures.Issuer is required")
+	}
+	token := fixtures.Issuer.Token()
+
+	t.Run("auth", func(t *testing.T) {
+		event := newEvent(t, uim.UIMCommandGetChannelInfo, requests[uim.UIMCommandGetChannelInfo])
+		tokens := map[string]string{
+			"missing":   "",
+			"malformed": "not.a.token",
+			"expired":   fixtures.Issuer.ExpiredToken(),
+			"audience":  fixtures.Issuer.TokenForAudience("providertest_other_audience"),
+		}
+		for name, token := range tokens {
+			t.Run(name, func(t *testing.T) {
+				status, body := send(handler, token, event)
+				expectError(t, status, body, uim.UnauthorizedErrorStatus, uim.UnauthorizedErrorCode)
+			})
+		}
+	})
+
+	t.Run("format", func(t *testing.T) {
+		status, body := send(handler, token, []byte("not a cloudevent"))
+		expectError(t, status, body, uim.InvalidEventFormatErrorStatus, uim.InvalidEventFormatErrorCode)
+	})
+
+	// uim.get_capabilities 由 EventHandler 自动回复，列出的指令和 Fixtures.Commands 一致
+	t.Run(uim.UIMCommandGetCapabilities, func(t *testing.T) {
+		status, body := send(handler, token, newEvent(t, uim.UIMCommandGetCapabilities, requests[uim.UIMCommandGetCapabilities]))
+		resp := &uim.GetCapabilitiesResponse{}
+		expectResponse(t, status, body, resp)
+		handled := make(map[string]bool)
+		for _, eventType := range resp.EventTypes {
+			handled[eventType] = true
+		}
+		for _, commandType := range commandTypes() {
+			_, ok := fixtures.Commands[commandType]
+			if ok != handled[commandType] {
+				t.Errorf("capabilities lists %s: %v, fixtures list it: %v", commandType, handled[commandType], ok)
+			}
+		}
+	})
+
+	t.Run(uim.UIMCommandPing, func(t *testing.T) {
+		status, body := send(handler, token, newEvent(t, uim.UIMCommandPing, requests[uim.UIMCommandPing]))
+		expectResponse(t, status, body, &uim.PingResponse{})
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		types := []string{"uim.providertest_unknown"}
+		for _, commandType := range commandTypes() {
+			if _, ok := fixtures.Commands[commandType]; !ok {
+				types = append(types, commandType)
+			}
+		}
+		for _, commandType := range types {
+			t.Run(commandType, func(t *testing.T) {
+				var data any = map[string]any{}
+				if request, ok := requests[commandType]; ok {
+					data = request
+				}
+				status, body := send(handler, token, newEvent(t, commandType, data))
+				expectError(t, status, body, uim.UnsupportedEventTypeErrorStatus, uim.UnsupportedEventTypeErrorCode)
+			})
+		}
+	})
+
+	for _, commandType := range commandTypes() {
+		data, ok := fixtures.Commands[commandType]
+		if !ok {
+			continue
+		}
+		info, _ := uim.LookupEventType(commandType)
+		if data == nil {
+			data = requests[commandType]
+		}
+		if data == nil {
+			data = info.NewData()
+		}
+		t.Run(commandType, func(t *testing.T) {
+			event := newEvent(t, commandType, data)
+			status, body := send(handler, token, event)
+			expectResponse(t, status, body, info.NewResponse())
+
+			if fixtures.SkipIdempotency {
+				return
+			}
+			// 重复投递的事件 ID 相同，应返回相同的结果
+			retryStatus, retryBody := send(handler, token, event)
+			if retryStatus != status || !bytes.Equal(canonicalJSON(retryBody), canonicalJSON(body)) {
+				t.Errorf("redelivered event %s returned %d %s, want %d %s", commandType, retryStatus, retryBody, status, body)
+			}
+		})
+	}
+}
+
+// 注册的 UIM 调用 provider 的指令，按类型排序，不包括自动回复的指令
+func commandTypes() []string {
+	var types []string
+	for _, info := range uim.EventTypes() {
+		if info.IsCommand() && info.Sender == uim.EventSenderUIM && !uim.IsBuiltinCommand(info.Type) {
+			types = append(types, info.Type)
+		}
+	}
+	return types
+}
+
+func newEvent(t *testing.T, eventType string, data any) []byte {
+	t.Helper()
+	id, _ := gonanoid.New()
+	event := cloudevents.NewEvent()
+	event.SetID(id)
+	event.SetSource(EventSource)
+	event.SetType(eventType)
+	if err := event.SetData(cloudevents.ApplicationJSON, data); err != nil {
+		t.Fatalf("providertest: invalid data for %s: %v", eventType, err)
+	}
+	content, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("providertest: invalid event %s: %v", eventType, err)
+	}
+	return content
+}
+
+func send(handler http.Handler, token string, content []byte) (int, []byte) {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(content))
+	req.Header.Set("Content-Type", cloudevents.ApplicationJSON)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder.Code, recorder.Body.Bytes()
+}
+
+func expectError(t *testing.T, status int, body []byte, wantStatus int, wantCode string) {
+	t.Helper()
+	if status != wantStatus {
+		t.Errorf("status = %d, want %d, body: %s", status, wantStatus, body)
+	}
+	var e errorBody
+	if err := json.Unmarshal(body, &e); err != nil {
+		t.Errorf("error response is not json: %s", body)
+		return
+	}
+	if e.Code != wantCode {
+		t.Errorf("code = %q, want %q, message: %s", e.Code, wantCode, e.Message)
+	}
+}
+
+// 检查返回成功，且返回数据只包含返回类型中定义的字段
+func expectResponse(t *testing.T, status int, body []byte, response any) {
+	t.Helper()
+	if status != http.StatusOK {
+		var e errorBody
+		_ = json.Unmarshal(body, &e)
+		t.Errorf("status = %d, want %d, code: %s, message: %s", status, http.StatusOK, e.Code, e.Message)
+		return
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(response); err != nil {
+		t.Errorf("response does not match %T: %v, body: %s", response, err, strings.TrimSpace(string(body)))
+	}
+}
+
+func canonicalJSON(content []byte) []byte {
+	var value any
+	if err := json.Unmarshal(content, &value); err != nil {
+		return content
+	}
+	canonical, _ := json.Marshal(value)
+	return canonical
+}
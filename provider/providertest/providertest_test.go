@@ -0,0 +1,48 @@
+package providertest
+
+import (
+	"sync"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	uim "github.com/uimkit/provider-go"
+	"github.com/uimkit/provider-go/provider"
+)
+
+func TestRun(t *testing.T) {
+	issuer := NewIssuer("providertest")
+	defer issuer.Close()
+
+	client := provider.NewClient(issuer.ServerOption())
+	var lock sync.Mutex
+	sent := make(map[string]*uim.SendMessageResponse)
+	client.OnSendMessage(func(event *cloudevents.Event, req *uim.SendMessageRequest) (*uim.SendMessageResponse, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		// 重复投递的事件返回第一次发送的消息
+		if resp, ok := sent[event.ID()]; ok {
+			return resp, nil
+		}
+		resp := &uim.SendMessageResponse{Message: uim.Message{
+			MessageId: "m" + string(rune('0'+len(sent))),
+			Account:   req.Account,
+			Channel:   req.Channel,
+			UserId:    req.Account,
+			Type:      req.Type,
+			Text:      req.Text,
+		}}
+		sent[event.ID()] = resp
+		return resp, nil
+	})
+	client.OnGetChannelInfo(func(event *cloudevents.Event, req *uim.GetChannelInfoRequest) (*uim.GetChannelInfoResponse, error) {
+		return &uim.GetChannelInfoResponse{User: &uim.IMUser{UserId: req.Channel}}, nil
+	})
+
+	Run(t, client.EventHandler(), Fixtures{
+		Issuer: issuer,
+		Commands: map[string]any{
+			uim.UIMCommandSendMessage:    nil,
+			uim.UIMCommandGetChannelInfo: &uim.GetChannelInfoRequest{Channel: "c1"},
+		},
+	})
+}
@@ -0,0 +1,24 @@
+package provider
+
+import (
+	"fmt"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	uim "github.com/uimkit/provider-go"
+)
+
+// 按账号限制发送消息指令的频率，超过限制时返回 429 错误，UIM 可以在 Retry-After 之后重试
+func LimitSendMessage(limiter *uim.RateLimiter, handler SendMessageHandler) SendMessageHandler {
+	return func(event *cloudevents.Event, req *uim.SendMessageRequest) (*uim.SendMessageResponse, error) {
+		if ok, retryAfter := limiter.Allow(req.Account); !ok {
+			return nil, uim.NewRetryableServerError(
+				uim.TooManyRequestsErrorStatus,
+				uim.TooManyRequestsErrorCode,
+				fmt.Sprintf(uim.TooManyRequestsErrorMessage, req.Account),
+				retryAfter,
+				nil,
+			)
+		}
+		return handler(event, req)
+	}
+}
@@ -0,0 +1,147 @@
+package uim
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+)
+
+// 限流器最多保留的令牌桶数量，超过后清理已装满的令牌桶
+const maxRateLimitBuckets = 10000
+
+// 令牌桶限流器，每个 key 使用独立的令牌桶
+type RateLimiter struct {
+	rate    float64 // 每秒生成的令牌数
+	burst   float64 // 令牌桶容量
+	lock    sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rate 是每秒允许的请求数，burst 是允许的突发请求数，rate 小于等于 0 时不限流，和 WithRateLimit 一致
+func NewRateLimiter(rate float64, burst int32) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+func (limiter *RateLimiter) bucket(key string, now time.Time) *tokenBucket {
+	bucket, ok := limiter.buckets[key]
+	if !ok {
+		if len(limiter.buckets) >= maxRateLimitBuckets {
+			limiter.cleanup(now)
+		}
+		bucket = &tokenBucket{tokens: limiter.burst, last: now}
+		limiter.buckets[key] = bucket
+		return bucket
+	}
+	bucket.tokens = math.Min(limiter.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*limiter.rate)
+	bucket.last = now
+	return bucket
+}
+
+func (limiter *RateLimiter) cleanup(now time.Time) {
+	for key, bucket := range limiter.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*limiter.rate >= limiter.burst {
+			delete(limiter.buckets, key)
+		}
+	}
+}
+
+// rate 小于等于 0 时不生成令牌，不限流
+func (limiter *RateLimiter) unlimited() bool {
+	return limiter.rate <= 0
+}
+
+// 令牌不足时的等待时间
+func (limiter *RateLimiter) delay(tokens float64) time.Duration {
+	if tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - tokens) / limiter.rate * float64(time.Second))
+}
+
+// 尝试获取一个令牌，获取失败时返回需要等待的时间
+func (limiter *RateLimiter) Allow(key string) (bool, time.Duration) {
+	if limiter.unlimited() {
+		return true, 0
+	}
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+
+	bucket := limiter.bucket(key, time.Now())
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	return false, limiter.delay(bucket.tokens)
+}
+
+// 获取一个令牌，令牌不足时阻塞等待，ctx 取消时归还令牌并返回 ctx 的错误
+func (limiter *RateLimiter) Wait(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if limiter.unlimited() {
+		return nil
+	}
+	limiter.lock.Lock()
+	bucket := limiter.bucket(key, time.Now())
+	wait := limiter.delay(bucket.tokens)
+	// 预支令牌，保证等待的请求按顺序获得令牌
+	bucket.tokens--
+	limiter.lock.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		limiter.lock.Lock()
+		bucket = limiter.bucket(key, time.Now())
+		bucket.tokens = math.Min(limiter.burst, bucket.tokens+1)
+		limiter.lock.Unlock()
+		return ctx.Err()
+	}
+}
+
+// 从请求的事件中获取事件类型和事件数据中的账号
+func getEventInfo(request Request) (eventType, account string) {
+	event := struct {
+		Type string `json:"type"`
+		Data struct {
+			Account string `json:"account"`
+		} `json:"data"`
+	}{}
+	if err := json.Unmarshal(request.GetContent(), &event); err != nil {
+		return "", ""
+	}
+	return event.Type, event.Data.Account
+}
+
+func (client *Client) waitRateLimit(ctx context.Context, account string) error {
+	if client.rateLimiter != nil {
+		if err := client.rateLimiter.Wait(ctx, ""); err != nil {
+			return err
+		}
+	}
+	if client.accountRateLimiter != nil && account != "" {
+		return client.accountRateLimiter.Wait(ctx, account)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package uim
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(10, 2)
+	ok, _ := limiter.Allow("a")
+	assert.True(t, ok)
+	ok, _ = limiter.Allow("a")
+	assert.True(t, ok)
+	ok, retryAfter := limiter.Allow("a")
+	assert.False(t, ok)
+	assert.Greater(t, retryAfter, time.Duration(0))
+	assert.LessOrEqual(t, retryAfter, 100*time.Millisecond)
+
+	// 不同账号使用独立的令牌桶
+	ok, _ = limiter.Allow("b")
+	assert.True(t, ok)
+
+	start := time.Now()
+	assert.Nil(t, limiter.Wait(context.Background(), "a"))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	for _, rate := range []float64{0, -1} {
+		limiter := NewRateLimiter(rate, 1)
+		for i := 0; i < 3; i++ {
+			ok, retryAfter := limiter.Allow("a")
+			assert.True(t, ok)
+			assert.Equal(t, time.Duration(0), retryAfter)
+			assert.Nil(t, limiter.Wait(context.Background(), "a"))
+		}
+	}
+}
+
+func TestRateLimiterWaitCanceled(t *testing.T) {
+	limiter := NewRateLimiter(1, 1)
+	assert.Nil(t, limiter.Wait(context.Background(), "a"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := limiter.Wait(ctx, "a")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "%v", err)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	// 取消的等待归还令牌，不影响后面的请求
+	limiter.lock.Lock()
+	tokens := limiter.buckets["a"].tokens
+	limiter.lock.Unlock()
+	assert.Greater(t, tokens, -0.5)
+}
+
+func TestAccountRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAuthorization(false),
+		WithEventSource("provider.source/test/test"),
+		WithBaseUrl(server.URL),
+		WithAccountRateLimit(20, 1),
+	)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		err := client.SendEvent(ProviderEventNewMessage, &Message{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u1", Type: MessageTypeText, Text: "hello"})
+		assert.Nil(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestWriteRetryAfter(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	writeError(recorder, NewRetryableServerError(TooManyRequestsErrorStatus, TooManyRequestsErrorCode, "", 1500*time.Millisecond, nil))
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "2", recorder.Header().Get("Retry-After"))
+}
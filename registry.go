@@ -0,0 +1,260 @@
+package uim
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// 事件或指令的发送方
+type EventSender string
+
+const (
+	EventSenderProvider EventSender = "provider" // Provider 发送给 UIM
+	EventSenderUIM      EventSender = "uim"      // UIM 发送给 Provider
+)
+
+// 事件或指令类型的定义
+type EventTypeInfo struct {
+	Type     string       // 事件或指令类型
+	Summary  string       // 说明
+	Sender   EventSender  // 发送方
+	Data     reflect.Type // 数据的结构体类型，发送时使用指针，nil 表示还没有定义数据模型
+	Response reflect.Type // 指令返回的结构体类型，事件为 nil
+}
+
+// 是否是指令
+func (info EventTypeInfo) IsCommand() bool {
+	return info.Response != nil
+}
+
+// 创建数据，没有定义数据模型时返回 map
+func (info EventTypeInfo) NewData() any {
+	if info.Data == nil {
+		return &map[string]any{}
+	}
+	return reflect.New(info.Data).Interface()
+}
+
+// 创建指令的返回，事件返回 nil
+func (info EventTypeInfo) NewResponse() Response {
+	if info.Response == nil {
+		return nil
+	}
+	return reflect.New(info.Response).Interface().(Response)
+}
+
+var registry = struct {
+	sync.RWMutex
+	types map[string]EventTypeInfo
+}{types: make(map[string]EventTypeInfo)}
+
+var responseType = reflect.TypeOf((*Response)(nil)).Elem()
+
+func typeOf[T any]() reflect.Type {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() == reflect.Interface {
+		return nil
+	}
+	return t
+}
+
+func register(info EventTypeInfo) {
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.types[info.Type]; ok {
+		panic(fmt.Sprintf("uim: event type %q registered twice", info.Type))
+	}
+	registry.types[info.Type] = info
+}
+
+// 注册事件类型，D 为数据的结构体类型，D 为 any 时表示没有定义数据模型，重复注册会 panic
+func RegisterEvent[D any](eventType, summary string, sender EventSender) {
+	register(EventTypeInfo{Type: eventType, Summary: summary, Sender: sender, Data: typeOf[D]()})
+}
+
+// 注册指令类型，D 为请求数据的结构体类型，R 为返回的结构体类型，*R 需要实现 Response
+func RegisterCommand[D any, R any](commandType, summary string, sender EventSender) {
+	response := typeOf[R]()
+	if response == nil || !reflect.PointerTo(response).Implements(responseType) {
+		panic(fmt.Sprintf("uim: response of command %q must implement uim.Response", commandType))
+	}
+	register(EventTypeInfo{Type: commandType, Summary: summary, Sender: sender, Data: typeOf[D](), Response: response})
+}
+
+// 查询事件或指令类型的定义
+func LookupEventType(eventType string) (EventTypeInfo, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	info, ok := registry.types[eventType]
+	return info, ok
+}
+
+// 所有注册的事件和指令类型，按类型排序
+func EventTypes() []EventTypeInfo {
+	registry.RLock()
+	defer registry.RUnlock()
+	types := make([]EventTypeInfo, 0, len(registry.types))
+	for _, info := range registry.types {
+		types = append(types, info)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].Type < types[j].Type
+	})
+	return types
+}
+
+func mismatchedEventDataError(format string, args ...any) Error {
+	return NewClientError(MismatchedEventDataErrorCode, fmt.Sprintf(format, args...), nil)
+}
+
+// 发送事件前检查数据类型，没有注册的类型不检查
+func checkEventData(eventType string, data any) error {
+	info, ok := LookupEventType(eventType)
+	if !ok {
+		return nil
+	}
+	if info.IsCommand() {
+		return mismatchedEventDataError(MismatchedEventKindErrorMessage, eventType, "a command", "Invoke")
+	}
+	return checkDataType(info, data)
+}
+
+// 调用指令前检查请求和返回的类型，没有注册的类型不检查
+func checkCommandData(commandType string, data any, resp Response) error {
+	info, ok := LookupEventType(commandType)
+	if !ok {
+		return nil
+	}
+	if !info.IsCommand() {
+		return mismatchedEventDataError(MismatchedEventKindErrorMessage, commandType, "an event", "SendEvent")
+	}
+	if err := checkDataType(info, data); err != nil {
+		return err
+	}
+	if t := reflect.TypeOf(resp); t == nil || t.Kind() != reflect.Pointer || t.Elem() != info.Response {
+		return mismatchedEventDataError(MismatchedEventDataErrorMessage, commandType, "response", info.Response, resp)
+	}
+	return nil
+}
+
+func checkDataType(info EventTypeInfo, data any) error {
+	if info.Data == nil {
+		return nil
+	}
+	t := reflect.TypeOf(data)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t != info.Data {
+		return mismatchedEventDataError(MismatchedEventDataErrorMessage, info.Type, "data", info.Data, data)
+	}
+	return nil
+}
+
+// 注册处理函数前检查类型，不一致时 panic
+func mustMatchHandler(eventType string, data, response reflect.Type) {
+	info, ok := LookupEventType(eventType)
+	if !ok {
+		return
+	}
+	if info.IsCommand() != (response != nil) {
+		panic(fmt.Sprintf("uim: handler of %q must use HandleEvent for events and HandleCommand for commands", eventType))
+	}
+	if info.Data != nil && data != info.Data {
+		panic(fmt.Sprintf("uim: handler of %q must accept *%s, got *%s", eventType, info.Data, data))
+	}
+	if response != nil && response != info.Response {
+		panic(fmt.Sprintf("uim: handler of %q must return *%s, got *%s", eventType, info.Response, response))
+	}
+}
+
+// 注册事件的处理函数，D 和注册的数据类型不一致时 panic
+func HandleEvent[D any](client *Client, eventType string, handler func(*cloudevents.Event, *D) error) {
+	mustMatchHandler(eventType, typeOf[D](), nil)
+	client.OnEvent(eventType, CastEventHandler(handler))
+}
+
+// 注册指令的处理函数，D、R 和注册的请求、返回类型不一致时 panic
+func HandleCommand[D any, R any](client *Client, commandType string, handler func(*cloudevents.Event, *D) (*R, error)) {
+	mustMatchHandler(commandType, typeOf[D](), typeOf[R]())
+	client.OnEvent(commandType, CastCommandHandler(handler))
+}
+
+// 处理指令后检查返回的类型并校验返回的数据，没有注册的类型和已编码的 JSON 不检查
+func checkCommandResponse(commandType string, resp any) error {
+	info, ok := LookupEventType(commandType)
+	if !ok || !info.IsCommand() || resp == nil {
+		return nil
+	}
+	if _, ok := resp.(json.RawMessage); ok {
+		return nil
+	}
+	if t := reflect.TypeOf(resp); t.Kind() != reflect.Pointer || t.Elem() != info.Response {
+		return NewServerError(
+			MismatchedEventDataErrorStatus,
+			MismatchedEventDataErrorCode,
+			fmt.Sprintf(MismatchedEventDataErrorMessage, commandType, "response", info.Response, resp),
+			nil,
+		)
+	}
+	if err := validateData(resp); err != nil {
+		return NewServerError(InvalidResponseDataErrorStatus, InvalidResponseDataErrorCode, fmt.Sprintf(InvalidResponseDataErrorMessage, commandType, err.Error()), err)
+	}
+	return nil
+}
+
+func init() {
+	RegisterEvent[IMAccount](ProviderEventNewAccount, "新账号", EventSenderProvider)
+	RegisterEvent[IMAccountUpdate](ProviderEventAccountUpdated, "账号更新", EventSenderProvider)
+	RegisterEvent[Contact](ProviderEventNewContact, "新好友", EventSenderProvider)
+	RegisterEvent[Follower](ProviderEventNewFollower, "新粉丝", EventSenderProvider)
+	RegisterEvent[Following](ProviderEventNewFollowing, "新关注的人", EventSenderProvider)
+	RegisterEvent[FriendApply](ProviderEventNewFriendApply, "新的好友申请", EventSenderProvider)
+	RegisterEvent[Message](ProviderEventNewMessage, "收新消息", EventSenderProvider)
+	RegisterEvent[MessageUpdate](ProviderEventMessageUpdated, "消息更新，如：撤回消息", EventSenderProvider)
+	RegisterEvent[Reaction](ProviderEventNewMessageReaction, "收到消息的表情回应", EventSenderProvider)
+	RegisterEvent[Reaction](ProviderEventMessageReactionDeleted, "消息的表情回应被取消", EventSenderProvider)
+	RegisterEvent[MessageStatusChange](ProviderEventMessageStatusChanged, "消息的投递状态变更，如：送达、已读", EventSenderProvider)
+	RegisterEvent[Metafield](ProviderEventNewMetafield, "新的元信息", EventSenderProvider)
+	RegisterEvent[MetafieldUpdate](ProviderEventMetafieldUpdated, "元信息更新", EventSenderProvider)
+	RegisterEvent[any](ProviderEventNewFriendReply, "收到好友申请回复", EventSenderProvider)
+	RegisterEvent[Group](ProviderEventNewGroup, "新群组", EventSenderProvider)
+	RegisterEvent[GroupUpdate](ProviderEventGroupUpdated, "群组更新", EventSenderProvider)
+	RegisterEvent[any](ProviderEventGroupDeleted, "群组删除", EventSenderProvider)
+	RegisterEvent[GroupMember](ProviderEventNewGroupMember, "新群成员", EventSenderProvider)
+	RegisterEvent[GroupMemberUpdate](ProviderEventGroupMemberUpdated, "群成员更新", EventSenderProvider)
+	RegisterEvent[any](ProviderEventGroupMemberDeleted, "群成员删除", EventSenderProvider)
+	RegisterEvent[GroupInvitation](ProviderEventNewGroupInvitation, "收到入群邀请", EventSenderProvider)
+	RegisterEvent[GroupApply](ProviderEventNewGroupApply, "收到入群申请", EventSenderProvider)
+	RegisterEvent[Moment](ProviderEventNewMoment, "新动态", EventSenderProvider)
+	RegisterEvent[any](ProviderEventMomentUpdated, "动态更新", EventSenderProvider)
+	RegisterEvent[any](ProviderEventMomentDeleted, "动态删除", EventSenderProvider)
+	RegisterEvent[any](ProviderEventNewMomentComment, "收到动态评论", EventSenderProvider)
+	RegisterEvent[any](ProviderEventMomentCommentUpdated, "动态评论更新", EventSenderProvider)
+	RegisterEvent[any](ProviderEventMomentCommentDeleted, "动态评论被删除", EventSenderProvider)
+	RegisterEvent[any](ProviderEventNewMomentLike, "收到动态点赞", EventSenderProvider)
+	RegisterEvent[any](ProviderEventMomentLikeDeleted, "动态点赞被删除", EventSenderProvider)
+
+	RegisterCommand[GetMetafieldRequest, GetMetafieldResponse](ProviderCommandGetMetafield, "查询元信息", EventSenderProvider)
+
+	RegisterCommand[GetChannelInfoRequest, GetChannelInfoResponse](UIMCommandGetChannelInfo, "查询消息地址关联的信息", EventSenderUIM)
+	RegisterCommand[SendMessageRequest, SendMessageResponse](UIMCommandSendMessage, "发送消息", EventSenderUIM)
+	RegisterCommand[AddContactRequest, AddContactResponse](UIMCommandAddContact, "发起好友申请", EventSenderUIM)
+	RegisterCommand[AcceptFriendApplyRequest, AcceptFriendApplyResponse](UIMCommandAcceptFriendApply, "通过好友请求", EventSenderUIM)
+	RegisterCommand[GetMomentListRequest, GetMomentListResponse](UIMCommandGetMomentList, "获取动态列表", EventSenderUIM)
+	RegisterCommand[SetGroupMuteRequest, SetGroupMuteResponse](UIMCommandSetGroupMute, "设置群组禁言", EventSenderUIM)
+	RegisterCommand[PublishMomentRequest, PublishMomentResponse](UIMCommandPublishMoment, "发布动态", EventSenderUIM)
+	RegisterCommand[RevokeMessageRequest, RevokeMessageResponse](UIMCommandRevokeMessage, "撤回消息", EventSenderUIM)
+	RegisterCommand[EditMessageRequest, EditMessageResponse](UIMCommandEditMessage, "编辑消息", EventSenderUIM)
+	RegisterCommand[DeleteMessageRequest, DeleteMessageResponse](UIMCommandDeleteMessage, "删除消息", EventSenderUIM)
+	RegisterCommand[ForwardMessageRequest, ForwardMessageResponse](UIMCommandForwardMessage, "转发消息", EventSenderUIM)
+	RegisterCommand[ReactMessageRequest, ReactMessageResponse](UIMCommandReactMessage, "回应或取消回应消息的表情", EventSenderUIM)
+	RegisterCommand[MarkReadRequest, MarkReadResponse](UIMCommandMarkRead, "将会话标记为已读", EventSenderUIM)
+	RegisterCommand[GetCapabilitiesRequest, GetCapabilitiesResponse](UIMCommandGetCapabilities, "查询 Provider 支持的能力", EventSenderUIM)
+	RegisterCommand[PingRequest, PingResponse](UIMCommandPing, "验证连接和认证", EventSenderUIM)
+}
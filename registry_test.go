@@ -0,0 +1,119 @@
+package uim
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventTypes(t *testing.T) {
+	info, ok := LookupEventType(UIMCommandSendMessage)
+	assert.True(t, ok)
+	assert.True(t, info.IsCommand())
+	assert.Equal(t, EventSenderUIM, info.Sender)
+	assert.IsType(t, &SendMessageRequest{}, info.NewData())
+	assert.IsType(t, &SendMessageResponse{}, info.NewResponse())
+
+	info, ok = LookupEventType(ProviderEventGroupDeleted)
+	assert.True(t, ok)
+	assert.False(t, info.IsCommand())
+	assert.Nil(t, info.Data)
+	assert.IsType(t, &map[string]any{}, info.NewData())
+
+	_, ok = LookupEventType(UIMCommandUpdateAccount)
+	assert.False(t, ok)
+
+	types := EventTypes()
+	for i := 1; i < len(types); i++ {
+		assert.Less(t, types[i-1].Type, types[i].Type)
+	}
+}
+
+func TestRegisterEventType(t *testing.T) {
+	RegisterCommand[GetChannelInfoRequest, GetChannelInfoResponse]("test.registry_command", "测试指令", EventSenderUIM)
+	info, ok := LookupEventType("test.registry_command")
+	assert.True(t, ok)
+	assert.Equal(t, "测试指令", info.Summary)
+
+	assert.Panics(t, func() {
+		RegisterEvent[Message]("test.registry_command", "重复注册", EventSenderProvider)
+	})
+	assert.Panics(t, func() {
+		RegisterCommand[Message, Message]("test.registry_invalid_response", "返回没有实现 Response", EventSenderUIM)
+	})
+}
+
+func TestSendEventMismatchedData(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAuthorization(false),
+		WithEventSource("provider.source/test/test"),
+		WithBaseUrl(server.URL),
+	)
+	err := client.SendEvent(ProviderEventNewMessage, &Contact{})
+	assert.NotNil(t, err)
+	assert.Equal(t, MismatchedEventDataErrorCode, err.(*ClientError).ErrorCode())
+	assert.Equal(t, `"provider.new_message" requires data of type *uim.Message, got *uim.Contact`, err.(*ClientError).Message())
+
+	err = client.SendEvent(UIMCommandSendMessage, &SendMessageRequest{})
+	assert.Equal(t, `"uim.send_message" is a command, please use Invoke`, err.(*ClientError).Message())
+
+	_, err = client.Invoke(ProviderEventNewMessage, &Message{}, &SendMessageResponse{})
+	assert.Equal(t, `"provider.new_message" is an event, please use SendEvent`, err.(*ClientError).Message())
+
+	_, err = client.Invoke(ProviderCommandGetMetafield, &GetMetafieldRequest{}, &SendMessageResponse{})
+	assert.Equal(t, MismatchedEventDataErrorCode, err.(*ClientError).ErrorCode())
+	assert.Equal(t, 0, calls)
+}
+
+func TestHandleMismatchedType(t *testing.T) {
+	client := NewClient(WithEventAuthorization(false))
+	assert.Panics(t, func() {
+		HandleEvent(client, ProviderEventNewMessage, func(_ *cloudevents.Event, _ *Contact) error { return nil })
+	})
+	assert.Panics(t, func() {
+		HandleEvent(client, UIMCommandSendMessage, func(_ *cloudevents.Event, _ *SendMessageRequest) error { return nil })
+	})
+	assert.Panics(t, func() {
+		HandleCommand(client, UIMCommandSendMessage, func(_ *cloudevents.Event, _ *SendMessageRequest) (*AddContactResponse, error) {
+			return nil, nil
+		})
+	})
+	assert.Empty(t, client.HandledEventTypes())
+
+	HandleCommand(client, UIMCommandSendMessage, func(_ *cloudevents.Event, _ *SendMessageRequest) (*SendMessageResponse, error) {
+		return &SendMessageResponse{}, nil
+	})
+	HandleEvent(client, ProviderEventGroupDeleted, func(_ *cloudevents.Event, _ *map[string]any) error { return nil })
+	assert.Equal(t, []string{ProviderEventGroupDeleted, UIMCommandSendMessage}, client.HandledEventTypes())
+}
+
+func TestEventHandlerMismatchedResponse(t *testing.T) {
+	client := NewClient(WithEventAuthorization(false))
+	client.OnEvent(UIMCommandSendMessage, func(_ *cloudevents.Event) (any, error) {
+		return &AddContactResponse{}, nil
+	})
+
+	event := cloudevents.NewEvent()
+	event.SetID("e1")
+	event.SetSource("uim")
+	event.SetType(UIMCommandSendMessage)
+	_ = event.SetData(cloudevents.ApplicationJSON, &SendMessageRequest{Account: "a1", Channel: "c1", Type: MessageTypeText, Text: "hello"})
+	body, _ := json.Marshal(&event)
+
+	recorder := httptest.NewRecorder()
+	client.EventHandler()(recorder, httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(string(body))))
+	assert.Equal(t, MismatchedEventDataErrorStatus, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), MismatchedEventDataErrorCode)
+}
@@ -2,6 +2,7 @@ package uim
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -71,6 +72,34 @@ type Request interface {
 	BuildUrl() string
 }
 
+// 可以携带 context 的请求，BaseRequest 实现了该接口
+type contextRequest interface {
+	SetContext(ctx context.Context)
+	GetContext() context.Context
+}
+
+// 获取请求携带的 context，没有时返回 context.Background()
+func requestContext(request Request) context.Context {
+	if r, ok := request.(contextRequest); ok && r.GetContext() != nil {
+		return r.GetContext()
+	}
+	return context.Background()
+}
+
+// 可以设置日志回调的请求，BaseRequest 实现了该接口
+type logHookRequest interface {
+	SetLogHook(hook LogHook)
+	GetLogHook() LogHook
+}
+
+// 获取请求的日志回调，没有时返回 nil
+func requestLogHook(request Request) LogHook {
+	if r, ok := request.(logHookRequest); ok {
+		return r.GetLogHook()
+	}
+	return nil
+}
+
 type BaseRequest struct {
 	method         string
 	scheme         string
@@ -90,6 +119,8 @@ type BaseRequest struct {
 	userAgent      map[string]string
 	content        []byte
 	acceptFormat   string
+	ctx            context.Context
+	logHook        LogHook
 }
 
 func (request *BaseRequest) SetMethod(method string) {
@@ -259,6 +290,22 @@ func (request *BaseRequest) GetAcceptFormat() string {
 	return request.acceptFormat
 }
 
+func (request *BaseRequest) SetContext(ctx context.Context) {
+	request.ctx = ctx
+}
+
+func (request *BaseRequest) GetContext() context.Context {
+	return request.ctx
+}
+
+func (request *BaseRequest) SetLogHook(hook LogHook) {
+	request.logHook = hook
+}
+
+func (request *BaseRequest) GetLogHook() LogHook {
+	return request.logHook
+}
+
 func (request *BaseRequest) GetBodyReader() io.Reader {
 	if request.formParams != nil && len(request.formParams) > 0 {
 		formString := getUrlFormedMap(request.formParams)
@@ -628,3 +675,36 @@ func WithRequestBasePath(basePath string) RequestOption {
 		req.SetBasePath(basePath)
 	}
 }
+
+// 设置请求的 context，context 取消时请求随之取消
+func WithRequestContext(ctx context.Context) RequestOption {
+	return func(req Request) {
+		if r, ok := req.(contextRequest); ok {
+			r.SetContext(ctx)
+		}
+	}
+}
+
+// 设置请求的超时，只对当前请求生效
+func WithRequestTimeout(readTimeout, connectTimeout time.Duration) RequestOption {
+	return func(req Request) {
+		req.SetReadTimeout(readTimeout)
+		req.SetConnectTimeout(connectTimeout)
+	}
+}
+
+// 设置当前请求是否跳过证书验证
+func WithRequestHTTPSInsecure(insecure bool) RequestOption {
+	return func(req Request) {
+		req.SetHTTPSInsecure(insecure)
+	}
+}
+
+// 设置当前请求的日志回调，可以获取该请求每一次调用的日志
+func WithRequestLogHook(hook LogHook) RequestOption {
+	return func(req Request) {
+		if r, ok := req.(logHookRequest); ok {
+			r.SetLogHook(hook)
+		}
+	}
+}
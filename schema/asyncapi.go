@@ -0,0 +1,136 @@
+package schema
+
+import (
+	"reflect"
+
+	uim "github.com/uimkit/provider-go"
+)
+
+// AsyncAPI 版本
+const AsyncAPIVersion = "3.0.0"
+
+// AsyncAPI 文档，从 Provider 的角度描述：发送 provider.* 事件和指令，接收并回复 uim.* 指令
+type Document struct {
+	AsyncAPI           string                `json:"asyncapi"`
+	Info               Info                  `json:"info"`
+	DefaultContentType string                `json:"defaultContentType"`
+	Channels           map[string]*Channel   `json:"channels"`
+	Operations         map[string]*Operation `json:"operations"`
+	Components         Components            `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Reference struct {
+	Ref string `json:"$ref"`
+}
+
+// 每个事件或指令类型一个 channel，地址为类型
+type Channel struct {
+	Address     string                `json:"address"`
+	Description string                `json:"description,omitempty"`
+	Messages    map[string]*Reference `json:"messages"`
+}
+
+type Operation struct {
+	Action   string          `json:"action"`
+	Channel  *Reference      `json:"channel"`
+	Summary  string          `json:"summary,omitempty"`
+	Messages []*Reference    `json:"messages"`
+	Reply    *OperationReply `json:"reply,omitempty"`
+}
+
+// 指令的返回
+type OperationReply struct {
+	Channel  *Reference   `json:"channel"`
+	Messages []*Reference `json:"messages"`
+}
+
+type Message struct {
+	Name    string  `json:"name"`
+	Title   string  `json:"title,omitempty"`
+	Summary string  `json:"summary,omitempty"`
+	Payload *Schema `json:"payload"`
+}
+
+type Components struct {
+	Messages map[string]*Message `json:"messages"`
+	Schemas  map[string]*Schema  `json:"schemas"`
+}
+
+// 指令返回的消息名
+func responseMessageName(eventType string) string {
+	return eventType + ".response"
+}
+
+// 事件和指令的 AsyncAPI 文档，数据模型定义在 components.schemas 中
+func AsyncAPI() *Document {
+	g := newGenerator("#/components/schemas/")
+	doc := &Document{
+		AsyncAPI: AsyncAPIVersion,
+		Info: Info{
+			Title:       "UIM Provider",
+			Version:     uim.Version,
+			Description: "Provider 和 UIM 之间的事件和指令，数据是 CloudEvents 的 data",
+		},
+		DefaultContentType: "application/json",
+		Channels:           make(map[string]*Channel),
+		Operations:         make(map[string]*Operation),
+		Components: Components{
+			Messages: make(map[string]*Message),
+		},
+	}
+	payload := func(t reflect.Type) *Schema {
+		if t == nil {
+			return &Schema{Type: "object", Description: "没有定义数据模型"}
+		}
+		return g.schema(t)
+	}
+
+	// 已废弃的指令没有注册，不导出
+	for _, info := range uim.EventTypes() {
+		channelRef := &Reference{Ref: "#/channels/" + info.Type}
+		messageRef := &Reference{Ref: "#/components/messages/" + info.Type}
+		channel := &Channel{
+			Address:     info.Type,
+			Description: info.Summary,
+			Messages:    map[string]*Reference{info.Type: messageRef},
+		}
+		doc.Components.Messages[info.Type] = &Message{
+			Name:    info.Type,
+			Title:   info.Summary,
+			Payload: payload(info.Data),
+		}
+		action := "send"
+		if info.Sender == uim.EventSenderUIM {
+			action = "receive"
+		}
+		operation := &Operation{
+			Action:   action,
+			Channel:  channelRef,
+			Summary:  info.Summary,
+			Messages: []*Reference{{Ref: channelRef.Ref + "/messages/" + info.Type}},
+		}
+		if info.IsCommand() {
+			name := responseMessageName(info.Type)
+			channel.Messages[name] = &Reference{Ref: "#/components/messages/" + name}
+			doc.Components.Messages[name] = &Message{
+				Name:    name,
+				Title:   info.Summary + "的返回",
+				Payload: payload(info.Response),
+			}
+			operation.Reply = &OperationReply{
+				Channel:  channelRef,
+				Messages: []*Reference{{Ref: channelRef.Ref + "/messages/" + name}},
+			}
+		}
+		doc.Channels[info.Type] = channel
+		doc.Operations[info.Type] = operation
+	}
+	doc.Components.Schemas = g.defs
+	return doc
+}
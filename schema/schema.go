@@ -0,0 +1,279 @@
+// 从 Go 模型生成事件和指令数据的 JSON Schema 以及 AsyncAPI 文档，供非 Go 的服务校验数据
+//
+// 生成的文件保存在本目录，修改模型或事件后运行 go generate ./schema 更新
+package schema
+
+//go:generate go run ../cmd/uimctl schema -out .
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	uim "github.com/uimkit/provider-go"
+)
+
+// JSON Schema 版本
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// 生成的文件名
+const (
+	JSONSchemaFile = "uim.schema.json"
+	AsyncAPIFile   = "asyncapi.json"
+)
+
+// JSON Schema，只包含生成模型用到的关键字
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// 字符串和整数枚举的取值，反射无法获取常量，新增枚举值时需要同步修改
+var enums = map[reflect.Type][]any{
+	reflect.TypeOf(uim.CursorDirection("")): {uim.CursorDirectionBefore, uim.CursorDirectionAfter},
+	reflect.TypeOf(uim.Gender(0)):           {uim.GenderUnknown, uim.GenderMale, uim.GenderFemale},
+	reflect.TypeOf(uim.Presence(0)): {
+		uim.PresenceInactive, uim.PresenceActive, uim.PresenceDisconnected, uim.PresenceDisabled, uim.PresenceBanned,
+	},
+	reflect.TypeOf(uim.ConversationType("")): {
+		uim.ConversationTypePrivate, uim.ConversationTypeGroup, uim.ConversationTypeDiscussion,
+		uim.ConversationTypeSystem, uim.ConversationTypeCustomerService,
+	},
+	reflect.TypeOf(uim.MessageType("")): {
+		uim.MessageTypeText, uim.MessageTypeImage, uim.MessageTypeAudio, uim.MessageTypeVideo,
+		uim.MessageTypeMiniProgram, uim.MessageTypeFile, uim.MessageTypeLink, uim.MessageTypeLocation,
+		uim.MessageTypeChatRecord,
+	},
+	reflect.TypeOf(uim.MessageStatus("")): {
+		uim.MessageStatusSending, uim.MessageStatusSent, uim.MessageStatusDelivered, uim.MessageStatusRead, uim.MessageStatusFailed,
+	},
+	reflect.TypeOf(uim.GroupMemberRole(0)): {uim.GroupMemberRoleMember, uim.GroupMemberRoleAdmin, uim.GroupMemberRoleOwner},
+	reflect.TypeOf(uim.MetafieldValueType("")): {
+		uim.MetafieldValueTypeInteger, uim.MetafieldValueTypeString, uim.MetafieldValueTypeBoolean,
+		uim.MetafieldValueTypeDateTime, uim.MetafieldValueTypeJsonArray, uim.MetafieldValueTypeJsonMap,
+		uim.MetafieldValueTypeDecimal,
+	},
+	reflect.TypeOf(uim.MomentType("")): {
+		uim.MomentTypeText, uim.MomentTypeImage, uim.MomentTypeVideo, uim.MomentTypeMiniProgram, uim.MomentTypeLink,
+	},
+	reflect.TypeOf(uim.MomentPrivacy("")): {
+		uim.MomentPrivacyPublic, uim.MomentPrivacyPrivate, uim.MomentPrivacyVisibleForUsers, uim.MomentPrivacyInvisibleForUsers,
+	},
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// 根据 Go 类型生成 Schema，结构体生成到 defs 中并返回引用
+type generator struct {
+	refPrefix string
+	defs      map[string]*Schema
+}
+
+func newGenerator(refPrefix string) *generator {
+	return &generator{refPrefix: refPrefix, defs: make(map[string]*Schema)}
+}
+
+// 包路径，如：github.com/uimkit/provider-go.
+var packagePath = regexp.MustCompile(`[\w./-]+\.`)
+
+// 定义名，泛型去掉包路径和符号，如：CursorPage[*Comment] 为 CursorPageComment
+func defName(t reflect.Type) string {
+	name := packagePath.ReplaceAllString(t.Name(), "")
+	return strings.NewReplacer("[", "", "]", "", "*", "", ",", "").Replace(name)
+}
+
+// 生成类型的 Schema，结构体返回引用
+func (g *generator) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	if values, ok := enums[t]; ok {
+		s := g.schema(underlying(t))
+		s.Enum = values
+		return s
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		s := &Schema{Type: "object"}
+		if t.Elem().Kind() != reflect.Interface {
+			s.AdditionalProperties = g.schema(t.Elem())
+		}
+		return s
+	case reflect.Struct:
+		name := defName(t)
+		if _, ok := g.defs[name]; !ok {
+			// 先占位，避免递归引用
+			g.defs[name] = &Schema{}
+			g.defs[name] = g.object(t)
+		}
+		return &Schema{Ref: g.refPrefix + name}
+	}
+	// interface 等任意类型
+	return &Schema{}
+}
+
+// 枚举的底层类型
+func underlying(t reflect.Type) reflect.Type {
+	switch t.Kind() {
+	case reflect.String:
+		return reflect.TypeOf("")
+	default:
+		return reflect.TypeOf(0)
+	}
+}
+
+// 结构体的 Schema，匿名嵌入的结构体字段展开，外层字段覆盖嵌入的同名字段
+func (g *generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Title: defName(t), Properties: make(map[string]*Schema)}
+	g.fields(t, s.Properties)
+	s.Required = required(t)
+	return s
+}
+
+func (g *generator) fields(t reflect.Type, properties map[string]*Schema) {
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, tagOptions, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded = append(embedded, field.Type)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		s := g.schema(field.Type)
+		// 没有 omitempty 的 slice、map 和指针为空时编码为 null
+		switch field.Type.Kind() {
+		case reflect.Slice, reflect.Map, reflect.Pointer:
+			if !strings.Contains(tagOptions, "omitempty") {
+				s = &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+			}
+		}
+		properties[name] = s
+	}
+	for _, e := range embedded {
+		for e.Kind() == reflect.Pointer {
+			e = e.Elem()
+		}
+		nested := make(map[string]*Schema)
+		g.fields(e, nested)
+		for name, s := range nested {
+			if _, ok := properties[name]; !ok {
+				properties[name] = s
+			}
+		}
+	}
+}
+
+// 必填字段，使用模型的 Validate 校验零值得到，和发送、接收时的校验保持一致
+func required(t reflect.Type) []string {
+	validator, ok := reflect.New(t).Interface().(uim.Validator)
+	if !ok {
+		return nil
+	}
+	errs, _ := validator.Validate().(uim.ValidationErrors)
+	var fields []string
+	for _, err := range errs {
+		if err.Message == "is required" && !strings.ContainsAny(err.Field, ".[") {
+			fields = append(fields, err.Field)
+		}
+	}
+	return fields
+}
+
+// 所有事件和指令数据的 JSON Schema，模型定义在 $defs 中
+func JSONSchema() *Schema {
+	g := newGenerator("#/$defs/")
+	for _, info := range uim.EventTypes() {
+		if info.Data != nil {
+			g.schema(info.Data)
+		}
+		if info.Response != nil {
+			g.schema(info.Response)
+		}
+	}
+	return &Schema{
+		Schema:      Draft,
+		Title:       "UIM",
+		Description: "UIM 事件和指令的数据模型",
+		Defs:        g.defs,
+	}
+}
+
+func marshal(v any) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// 生成的文件名和内容
+func Files() (map[string][]byte, error) {
+	jsonSchema, err := marshal(JSONSchema())
+	if err != nil {
+		return nil, err
+	}
+	asyncAPI, err := marshal(AsyncAPI())
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		JSONSchemaFile: jsonSchema,
+		AsyncAPIFile:   asyncAPI,
+	}, nil
+}
+
+// 生成文件到目录 dir
+func Write(dir string) error {
+	files, err := Files()
+	if err != nil {
+		return err
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
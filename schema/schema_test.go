@@ -0,0 +1,100 @@
+package schema
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	uim "github.com/uimkit/provider-go"
+)
+
+// 生成的文件需要和模型保持一致，修改模型或事件后运行 go generate ./schema
+func TestGeneratedFilesUpToDate(t *testing.T) {
+	files, err := Files()
+	assert.Nil(t, err)
+	for name, content := range files {
+		current, err := os.ReadFile(name)
+		assert.Nil(t, err)
+		assert.True(t, string(current) == string(content), "%s is out of date, run go generate ./schema", name)
+	}
+}
+
+// event.go 中除已废弃指令外的所有事件和指令类型
+func eventTypes(t *testing.T) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "../event.go", nil, parser.ParseComments)
+	assert.Nil(t, err)
+	var types []string
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		deprecated := false
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			if value.Doc != nil && strings.Contains(value.Doc.Text(), "deprecated") {
+				deprecated = true
+			}
+			if deprecated {
+				continue
+			}
+			for _, v := range value.Values {
+				if lit, ok := v.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					s, _ := strconv.Unquote(lit.Value)
+					types = append(types, s)
+				}
+			}
+		}
+	}
+	return types
+}
+
+func TestCatalogCoversEventTypes(t *testing.T) {
+	types := eventTypes(t)
+	assert.Contains(t, types, uim.ProviderEventNewMessage)
+	assert.NotContains(t, types, uim.UIMCommandUpdateAccount)
+
+	doc := AsyncAPI()
+	for _, eventType := range types {
+		assert.Contains(t, doc.Operations, eventType, "register %s in registry.go", eventType)
+	}
+	assert.Len(t, doc.Operations, len(types))
+}
+
+func TestJSONSchema(t *testing.T) {
+	defs := JSONSchema().Defs
+
+	message := defs["Message"]
+	assert.Equal(t, []string{"message_id", "channel", "account", "user_id", "type"}, message.Required)
+	assert.Contains(t, message.Properties["type"].Enum, uim.MessageTypeText)
+	assert.Equal(t, "#/$defs/ImageAttachment", message.Properties["image"].Ref)
+	assert.Equal(t, "date-time", message.Properties["sent_at"].Format)
+
+	// 嵌入的结构体字段展开
+	account := defs["IMAccount"]
+	assert.Contains(t, account.Properties, "user_id")
+	assert.Contains(t, account.Properties, "presence")
+
+	assert.Contains(t, defs, "CursorPageComment")
+	assert.NotContains(t, defs["SendMessageResponse"].Properties, "httpStatus")
+}
+
+func TestAsyncAPI(t *testing.T) {
+	doc := AsyncAPI()
+
+	send := doc.Operations[uim.ProviderEventNewMessage]
+	assert.Equal(t, "send", send.Action)
+	assert.Nil(t, send.Reply)
+	assert.Equal(t, "#/components/schemas/Message", doc.Components.Messages[uim.ProviderEventNewMessage].Payload.Ref)
+
+	command := doc.Operations[uim.UIMCommandSendMessage]
+	assert.Equal(t, "receive", command.Action)
+	assert.Equal(t, "#/channels/uim.send_message/messages/uim.send_message.response", command.Reply.Messages[0].Ref)
+	assert.Equal(t, "#/components/schemas/SendMessageResponse", doc.Components.Messages["uim.send_message.response"].Payload.Ref)
+	assert.Contains(t, doc.Components.Schemas, "SendMessageRequest")
+}
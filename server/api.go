@@ -11,119 +11,140 @@ import (
 type NewAccountHandler func(*cloudevents.Event, *uim.IMAccount) error
 
 func (client *Client) OnNewAccount(handler NewAccountHandler) {
-	client.OnEvent(uim.ProviderEventNewAccount, uim.CastEventHandler(handler))
+	uim.HandleEvent(client.Client, uim.ProviderEventNewAccount, handler)
 }
 
 // 账号更新
 type AccountUpdatedHandler func(*cloudevents.Event, *uim.IMAccountUpdate) error
 
 func (client *Client) OnAccountUpdated(handler AccountUpdatedHandler) {
-	client.OnEvent(uim.ProviderEventAccountUpdated, uim.CastEventHandler(handler))
+	uim.HandleEvent(client.Client, uim.ProviderEventAccountUpdated, handler)
 }
 
 // 新好友
 type NewContactHandler func(*cloudevents.Event, *uim.Contact) error
 
 func (client *Client) OnNewContact(handler NewContactHandler) {
-	client.OnEvent(uim.ProviderEventNewContact, uim.CastEventHandler(handler))
+	uim.HandleEvent(client.Client, uim.ProviderEventNewContact, handler)
 }
 
 // 新粉丝
 type NewFollowerHandler func(*cloudevents.Event, *uim.Follower) error
 
 func (client *Client) OnNewFollower(handler NewFollowerHandler) {
-	client.OnEvent(uim.ProviderEventNewFollower, uim.CastEventHandler(handler))
+	uim.HandleEvent(client.Client, uim.ProviderEventNewFollower, handler)
 }
 
 // 新关注的人
 type NewFollowingHandler func(*cloudevents.Event, *uim.Following) error
 
 func (client *Client) OnNewFollowing(handler NewFollowingHandler) {
-	client.OnEvent(uim.ProviderEventNewFollowing, uim.CastEventHandler(handler))
+	uim.HandleEvent(client.Client, uim.ProviderEventNewFollowing, handler)
 }
 
 // 新的好友申请
 type NewFriendApplyHandler func(*cloudevents.Event, *uim.FriendApply) error
 
 func (client *Client) OnNewFriendApply(handler NewFriendApplyHandler) {
-	client.OnEvent(uim.ProviderEventNewFriendApply, uim.CastEventHandler(handler))
+	uim.HandleEvent(client.Client, uim.ProviderEventNewFriendApply, handler)
 }
 
 // 新消息
 type NewMessageHandler func(*cloudevents.Event, *uim.Message) error
 
 func (client *Client) OnNewMessage(handler NewMessageHandler) {
-	client.OnEvent(uim.ProviderEventNewMessage, uim.CastEventHandler(handler))
+	uim.HandleEvent(client.Client, uim.ProviderEventNewMessage, handler)
 }
 
 // 消息更新
 type MessageUpdatedHandler func(*cloudevents.Event, *uim.MessageUpdate) error
 
 func (client *Client) OnMessageUpdated(handler MessageUpdatedHandler) {
-	client.OnEvent(uim.ProviderEventMessageUpdated, uim.CastEventHandler(handler))
+	uim.HandleEvent(client.Client, uim.ProviderEventMessageUpdated, handler)
+}
+
+// 收到消息的表情回应
+type NewMessageReactionHandler func(*cloudevents.Event, *uim.Reaction) error
+
+func (client *Client) OnNewMessageReaction(handler NewMessageReactionHandler) {
+	uim.HandleEvent(client.Client, uim.ProviderEventNewMessageReaction, handler)
+}
+
+// 消息的表情回应被取消
+type MessageReactionDeletedHandler func(*cloudevents.Event, *uim.Reaction) error
+
+func (client *Client) OnMessageReactionDeleted(handler MessageReactionDeletedHandler) {
+	uim.HandleEvent(client.Client, uim.ProviderEventMessageReactionDeleted, handler)
+}
+
+// 消息的投递状态变更
+type MessageStatusChangedHandler func(*cloudevents.Event, *uim.MessageStatusChange) error
+
+func (client *Client) OnMessageStatusChanged(handler MessageStatusChangedHandler) {
+	uim.HandleEvent(client.Client, uim.ProviderEventMessageStatusChanged, handler)
 }
 
 // 新群组
 type NewGroupHandler func(*cloudevents.Event, *uim.Group) error
 
 func (client *Client) OnNewGroup(handler NewGroupHandler) {
-	client.OnEvent(uim.ProviderEventNewGroup, uim.CastEventHandler(handler))
+	uim.HandleEvent(client.Client, uim.ProviderEventNewGroup, handler)
 }
 
 // 群组更新
 type GroupUpdatedHandler func(*cloudevents.Event, *uim.GroupUpdate) error
 
 func (client *Client) OnGroupUpdated(handler GroupUpdatedHandler) {
-	client.OnEvent(uim.ProviderEventGroupUpdated, uim.CastEventHandler(handler))
+	uim.HandleEvent(client.Client, uim.ProviderEventGroupUpdated, handler)
 }
 
 // 新群成员
 type NewGroupMemberHandler func(*cloudevents.Event, *uim.GroupMember) error
 
 func (client *Client) OnNewGroupMember(handler NewGroupMemberHandler) {
-	client.OnEvent(uim.ProviderEventNewGroupMember, uim.CastEventHandler(handler))
+	uim.HandleEvent(client.Client, uim.ProviderEventNewGroupMember, handler)
 }
 
 // 群成员更新
 type GroupMemberUpdatedHandler func(*cloudevents.Event, *uim.GroupMemberUpdate) error
 
 func (client *Client) OnGroupMemberUpdated(handler GroupMemberUpdatedHandler) {
-	client.OnEvent(uim.ProviderEventGroupMemberUpdated, uim.CastEventHandler(handler))
+	uim.HandleEvent(client.Client, uim.ProviderEventGroupMemberUpdated, handler)
 }
 
 // 收到入群邀请
 type NewGroupInvitationHandler func(*cloudevents.Event, *uim.GroupInvitation) error
 
 func (client *Client) OnNewGroupInvitation(handler NewGroupInvitationHandler) {
-	client.OnEvent(uim.ProviderEventNewGroupInvitation, uim.CastEventHandler(handler))
+	uim.HandleEvent(client.Client, uim.ProviderEventNewGroupInvitation, handler)
 }
 
 // 收到入群申请
 type NewGroupApplyHandler func(*cloudevents.Event, *uim.GroupApply) error
 
 func (client *Client) OnNewGroupApply(handler NewGroupApplyHandler) {
-	client.OnEvent(uim.ProviderEventNewGroupApply, uim.CastEventHandler(handler))
+	uim.HandleEvent(client.Client, uim.ProviderEventNewGroupApply, handler)
 }
 
 // 新的元数据
 type NewMetafieldHandler func(*cloudevents.Event, *uim.Metafield) error
 
 func (client *Client) OnNewMetafield(handler NewMetafieldHandler) {
-	client.OnEvent(uim.ProviderEventNewMetafield, uim.CastEventHandler(handler))
+	uim.HandleEvent(client.Client, uim.ProviderEventNewMetafield, handler)
 }
 
 // 元数据更新
 type MetafieldUpdatedHandler func(*cloudevents.Event, *uim.MetafieldUpdate) error
 
 func (client *Client) OnMetafieldUpdated(handler MetafieldUpdatedHandler) {
-	client.OnEvent(uim.ProviderEventMetafieldUpdated, uim.CastEventHandler(handler))
+	uim.HandleEvent(client.Client, uim.ProviderEventMetafieldUpdated, handler)
 }
 
 // 查询元数据
 type GetMetafieldHandler func(*cloudevents.Event, *uim.GetMetafieldRequest) (*uim.GetMetafieldResponse, error)
 
 func (client *Client) OnGetMetafield(handler GetMetafieldHandler) {
-	client.OnEvent(uim.ProviderCommandGetMetafield, uim.CastCommandHandler(handler))
+	uim.HandleCommand(client.Client, uim.ProviderCommandGetMetafield, handler)
 }
 
 // 查询消息地址关联的信息
@@ -150,6 +171,78 @@ func (client *Client) SendMessage(req *uim.SendMessageRequest, opts ...uim.Reque
 	)
 }
 
+// 撤回消息
+func (client *Client) RevokeMessage(req *uim.RevokeMessageRequest, opts ...uim.RequestOption) (*uim.RevokeMessageResponse, error) {
+	return uim.CastCommandResponse[*uim.RevokeMessageResponse](
+		client.Invoke(
+			uim.UIMCommandRevokeMessage,
+			req,
+			&uim.RevokeMessageResponse{},
+			opts...,
+		),
+	)
+}
+
+// 编辑消息
+func (client *Client) EditMessage(req *uim.EditMessageRequest, opts ...uim.RequestOption) (*uim.EditMessageResponse, error) {
+	return uim.CastCommandResponse[*uim.EditMessageResponse](
+		client.Invoke(
+			uim.UIMCommandEditMessage,
+			req,
+			&uim.EditMessageResponse{},
+			opts...,
+		),
+	)
+}
+
+// 删除消息
+func (client *Client) DeleteMessage(req *uim.DeleteMessageRequest, opts ...uim.RequestOption) (*uim.DeleteMessageResponse, error) {
+	return uim.CastCommandResponse[*uim.DeleteMessageResponse](
+		client.Invoke(
+			uim.UIMCommandDeleteMessage,
+			req,
+			&uim.DeleteMessageResponse{},
+			opts...,
+		),
+	)
+}
+
+// 转发消息
+func (client *Client) ForwardMessage(req *uim.ForwardMessageRequest, opts ...uim.RequestOption) (*uim.ForwardMessageResponse, error) {
+	return uim.CastCommandResponse[*uim.ForwardMessageResponse](
+		client.Invoke(
+			uim.UIMCommandForwardMessage,
+			req,
+			&uim.ForwardMessageResponse{},
+			opts...,
+		),
+	)
+}
+
+// 回应或取消回应消息的表情
+func (client *Client) ReactMessage(req *uim.ReactMessageRequest, opts ...uim.RequestOption) (*uim.ReactMessageResponse, error) {
+	return uim.CastCommandResponse[*uim.ReactMessageResponse](
+		client.Invoke(
+			uim.UIMCommandReactMessage,
+			req,
+			&uim.ReactMessageResponse{},
+			opts...,
+		),
+	)
+}
+
+// 将会话标记为已读
+func (client *Client) MarkRead(req *uim.MarkReadRequest, opts ...uim.RequestOption) (*uim.MarkReadResponse, error) {
+	return uim.CastCommandResponse[*uim.MarkReadResponse](
+		client.Invoke(
+			uim.UIMCommandMarkRead,
+			req,
+			&uim.MarkReadResponse{},
+			opts...,
+		),
+	)
+}
+
 // 发布朋友圈
 func (client *Client) PublishMoment(req *uim.PublishMomentRequest, opts ...uim.RequestOption) (*uim.PublishMomentResponse, error) {
 	return uim.CastCommandResponse[*uim.PublishMomentResponse](
@@ -186,6 +279,30 @@ func (client *Client) AddContact(req *uim.AddContactRequest, opts ...uim.Request
 	)
 }
 
+// 查询 Provider 支持的能力和可以处理的指令
+func (client *Client) GetCapabilities(opts ...uim.RequestOption) (*uim.GetCapabilitiesResponse, error) {
+	return uim.CastCommandResponse[*uim.GetCapabilitiesResponse](
+		client.Invoke(
+			uim.UIMCommandGetCapabilities,
+			&uim.GetCapabilitiesRequest{},
+			&uim.GetCapabilitiesResponse{},
+			opts...,
+		),
+	)
+}
+
+// 验证到 Provider 的连接和认证
+func (client *Client) Ping(opts ...uim.RequestOption) (*uim.PingResponse, error) {
+	return uim.CastCommandResponse[*uim.PingResponse](
+		client.Invoke(
+			uim.UIMCommandPing,
+			&uim.PingRequest{},
+			&uim.PingResponse{},
+			opts...,
+		),
+	)
+}
+
 // 设置群组禁言
 func (client *Client) SetGroupMute(req *uim.SetGroupMuteRequest, opts ...uim.RequestOption) (*uim.SetGroupMuteResponse, error) {
 	return uim.CastCommandResponse[*uim.SetGroupMuteResponse](
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+	uim "github.com/uimkit/provider-go"
+	"github.com/uimkit/provider-go/provider"
+)
+
+// 创建调用 provider 指令的 SDK，指令由 provider 的 EventHandler 处理
+func newServerClient(t *testing.T, p *provider.Client) *Client {
+	s := httptest.NewServer(p.EventHandler())
+	t.Cleanup(s.Close)
+	return NewClient(
+		uim.WithAuthorization(false),
+		WithServerName("test"),
+		uim.WithBaseUrl(s.URL),
+	)
+}
+
+func newProviderClient() *provider.Client {
+	return provider.NewClient(uim.WithEventAuthorization(false), provider.WithProvider("provider-go", "test"))
+}
+
+func TestRevokeEditDeleteMessage(t *testing.T) {
+	p := newProviderClient()
+	p.OnRevokeMessage(func(_ *cloudevents.Event, req *uim.RevokeMessageRequest) (*uim.RevokeMessageResponse, error) {
+		resp := &uim.RevokeMessageResponse{}
+		resp.Message = uim.Message{MessageId: req.MessageId, Channel: req.Channel, Account: req.Account, UserId: req.Account, Type: uim.MessageTypeText, Text: "hello", Revoked: true}
+		return resp, nil
+	})
+	p.OnEditMessage(func(_ *cloudevents.Event, req *uim.EditMessageRequest) (*uim.EditMessageResponse, error) {
+		resp := &uim.EditMessageResponse{}
+		resp.Message = uim.Message{MessageId: req.MessageId, Channel: req.Channel, Account: req.Account, UserId: req.Account, Type: uim.MessageTypeText, Text: req.Text, MentionedUsers: []string{req.MentionedUsers[0].UserId}}
+		return resp, nil
+	})
+	deleted := ""
+	p.OnDeleteMessage(func(_ *cloudevents.Event, req *uim.DeleteMessageRequest) (*uim.DeleteMessageResponse, error) {
+		deleted = req.MessageId
+		return &uim.DeleteMessageResponse{}, nil
+	})
+	client := newServerClient(t, p)
+
+	revoked, err := client.RevokeMessage(&uim.RevokeMessageRequest{Account: "a1", Channel: "c1", MessageId: "m1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "m1", revoked.MessageId)
+	assert.True(t, revoked.Revoked)
+
+	edited, err := client.EditMessage(&uim.EditMessageRequest{
+		Account:        "a1",
+		Channel:        "c1",
+		MessageId:      "m1",
+		Text:           "hello @u2",
+		MentionedUsers: []*uim.MessageMentionedUser{{UserId: "u2", StartAt: 6, EndAt: 9}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "hello @u2", edited.Text)
+	assert.Equal(t, []string{"u2"}, edited.MentionedUsers)
+
+	_, err = client.DeleteMessage(&uim.DeleteMessageRequest{Account: "a1", Channel: "c1", MessageId: "m1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "m1", deleted)
+
+	// 请求数据不合法时不会调用处理函数
+	_, err = client.EditMessage(&uim.EditMessageRequest{Account: "a1", Channel: "c1", MessageId: "m1"})
+	assert.Equal(t, uim.InvalidEventDataErrorCode, err.(*uim.ClientError).ErrorCode())
+}
+
+func TestSendMessageReference(t *testing.T) {
+	p := newProviderClient()
+	p.OnSendMessage(func(_ *cloudevents.Event, req *uim.SendMessageRequest) (*uim.SendMessageResponse, error) {
+		resp := &uim.SendMessageResponse{}
+		resp.Message = uim.Message{MessageId: "m2", Channel: req.Channel, Account: req.Account, UserId: req.Account, Type: req.Type, Text: req.Text, Reference: req.Reference}
+		return resp, nil
+	})
+	client := newServerClient(t, p)
+
+	sent, err := client.SendMessage(&uim.SendMessageRequest{
+		Account:   "a1",
+		Channel:   "c1",
+		Type:      uim.MessageTypeText,
+		Text:      "收到",
+		Reference: &uim.MessageReference{MessageId: "m1", UserId: "u2", Type: uim.MessageTypeImage, Snippet: "[图片]"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &uim.MessageReference{MessageId: "m1", UserId: "u2", Type: uim.MessageTypeImage, Snippet: "[图片]"}, sent.Reference)
+
+	// 引用的消息不合法时不会调用处理函数
+	_, err = client.SendMessage(&uim.SendMessageRequest{
+		Account:   "a1",
+		Channel:   "c1",
+		Type:      uim.MessageTypeText,
+		Text:      "收到",
+		Reference: &uim.MessageReference{Type: "unknown"},
+	})
+	assert.Equal(t, uim.InvalidEventDataErrorCode, err.(*uim.ClientError).ErrorCode())
+}
+
+func TestForwardMessage(t *testing.T) {
+	p := newProviderClient()
+	p.OnForwardMessage(func(_ *cloudevents.Event, req *uim.ForwardMessageRequest) (*uim.ForwardMessageResponse, error) {
+		record := &uim.ChatRecordAttachment{Title: req.Title}
+		for _, id := range req.MessageIds {
+			record.Messages = append(record.Messages, &uim.ChatRecordMessage{UserId: "u1", Type: uim.MessageTypeText, Text: id})
+		}
+		return &uim.ForwardMessageResponse{Messages: []*uim.Message{{
+			MessageId:  "m3",
+			Channel:    req.ToChannel,
+			Account:    req.Account,
+			UserId:     req.Account,
+			Type:       uim.MessageTypeChatRecord,
+			ChatRecord: record,
+		}}}, nil
+	})
+	client := newServerClient(t, p)
+
+	resp, err := client.ForwardMessage(&uim.ForwardMessageRequest{
+		Account:    "a1",
+		Channel:    "c1",
+		MessageIds: []string{"m1", "m2"},
+		ToChannel:  "c2",
+		Merge:      true,
+		Title:      "聊天记录",
+	})
+	assert.Nil(t, err)
+	assert.Len(t, resp.Messages, 1)
+	assert.Equal(t, "c2", resp.Messages[0].Channel)
+	assert.Equal(t, "聊天记录", resp.Messages[0].ChatRecord.Title)
+	assert.Len(t, resp.Messages[0].ChatRecord.Messages, 2)
+
+	_, err = client.ForwardMessage(&uim.ForwardMessageRequest{Account: "a1", Channel: "c1", ToChannel: "c2"})
+	assert.Equal(t, uim.InvalidEventDataErrorCode, err.(*uim.ClientError).ErrorCode())
+}
+
+func TestReactMessage(t *testing.T) {
+	p := newProviderClient()
+	p.OnReactMessage(func(_ *cloudevents.Event, req *uim.ReactMessageRequest) (*uim.ReactMessageResponse, error) {
+		if req.Remove {
+			return &uim.ReactMessageResponse{Reactions: []*uim.ReactionCount{}}, nil
+		}
+		return &uim.ReactMessageResponse{Reactions: []*uim.ReactionCount{{Emoji: req.Emoji, Count: 1, Reacted: true}}}, nil
+	})
+	client := newServerClient(t, p)
+
+	resp, err := client.ReactMessage(&uim.ReactMessageRequest{Account: "a1", Channel: "c1", MessageId: "m1", Emoji: "👍"})
+	assert.Nil(t, err)
+	assert.Equal(t, []*uim.ReactionCount{{Emoji: "👍", Count: 1, Reacted: true}}, resp.Reactions)
+
+	resp, err = client.ReactMessage(&uim.ReactMessageRequest{Account: "a1", Channel: "c1", MessageId: "m1", Emoji: "👍", Remove: true})
+	assert.Nil(t, err)
+	assert.Empty(t, resp.Reactions)
+}
+
+func TestMarkRead(t *testing.T) {
+	p := newProviderClient()
+	var marked []*uim.MarkReadRequest
+	p.OnMarkRead(func(_ *cloudevents.Event, req *uim.MarkReadRequest) (*uim.MarkReadResponse, error) {
+		marked = append(marked, req)
+		return &uim.MarkReadResponse{}, nil
+	})
+	client := newServerClient(t, p)
+
+	_, err := client.MarkRead(&uim.MarkReadRequest{Account: "a1", Channel: "c1", MessageId: "m1"})
+	assert.Nil(t, err)
+	_, err = client.MarkRead(&uim.MarkReadRequest{Account: "a1", Channel: "c1"})
+	assert.Nil(t, err)
+	assert.Equal(t, []*uim.MarkReadRequest{{Account: "a1", Channel: "c1", MessageId: "m1"}, {Account: "a1", Channel: "c1"}}, marked)
+
+	_, err = client.MarkRead(&uim.MarkReadRequest{Channel: "c1"})
+	assert.Equal(t, uim.InvalidEventDataErrorCode, err.(*uim.ClientError).ErrorCode())
+	assert.Len(t, marked, 2)
+}
@@ -0,0 +1,80 @@
+package uim
+
+import (
+	"context"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/uimkit/provider-go"
+
+// OpenTelemetry 的 TracerProvider
+type TracerProvider = trace.TracerProvider
+
+// cloudevents 分布式追踪扩展，使用 W3C trace context 格式
+var tracePropagator = propagation.TraceContext{}
+
+// 以 cloudevents 扩展属性读写 trace context
+type eventCarrier struct {
+	event *cloudevents.Event
+}
+
+func (carrier eventCarrier) Get(key string) string {
+	if value, ok := carrier.event.Extensions()[key]; ok {
+		if str, ok := value.(string); ok {
+			return str
+		}
+	}
+	return ""
+}
+
+func (carrier eventCarrier) Set(key, value string) {
+	carrier.event.SetExtension(key, value)
+}
+
+func (carrier eventCarrier) Keys() []string {
+	keys := make([]string, 0, len(carrier.event.Extensions()))
+	for key := range carrier.event.Extensions() {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// 获取事件携带的 trace context，在事件处理函数中调用 UIM 时传入 WithRequestContext 可以关联到同一个 trace
+func EventContext(event *cloudevents.Event) context.Context {
+	return tracePropagator.Extract(context.Background(), eventCarrier{event})
+}
+
+func (client *Client) tracer() trace.Tracer {
+	if client.options.TracerProvider != nil {
+		return client.options.TracerProvider.Tracer(tracerName, trace.WithInstrumentationVersion(Version))
+	}
+	return trace.NewNoopTracerProvider().Tracer(tracerName)
+}
+
+func (client *Client) startSpan(ctx context.Context, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return client.tracer().Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func eventAttributes(event *cloudevents.Event) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("cloudevents.event_id", event.ID()),
+		attribute.String("cloudevents.event_source", event.Source()),
+		attribute.String("cloudevents.event_type", event.Type()),
+	}
+}
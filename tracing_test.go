@@ -0,0 +1,97 @@
+package uim
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracePropagation(t *testing.T) {
+	received := make(chan *cloudevents.Event, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		event := cloudevents.NewEvent()
+		_ = json.Unmarshal(body, &event)
+		received <- &event
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	client := NewClient(
+		WithAuthorization(false),
+		WithEventSource("provider.source/test/test"),
+		WithBaseUrl(server.URL),
+		WithTracerProvider(tp),
+	)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	err := client.SendEvent(ProviderEventNewMessage, &Message{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u1", Type: MessageTypeText, Text: "hello"}, WithRequestContext(ctx))
+	assert.Nil(t, err)
+	parent.End()
+
+	event := <-received
+	assert.NotEmpty(t, event.Extensions()["traceparent"])
+	remote := trace.SpanContextFromContext(EventContext(event))
+	assert.True(t, remote.IsRemote())
+	assert.Equal(t, parent.SpanContext().TraceID(), remote.TraceID())
+
+	names := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		names[span.Name()] = span
+	}
+	send := names[ProviderEventNewMessage+" send"]
+	doAction := names["uim.DoAction"]
+	assert.NotNil(t, send)
+	assert.NotNil(t, doAction)
+	assert.Equal(t, parent.SpanContext().SpanID(), send.Parent().SpanID())
+	assert.Equal(t, send.SpanContext().SpanID(), doAction.Parent().SpanID())
+	// 事件携带的是发送事件的 span
+	assert.Equal(t, send.SpanContext().SpanID(), remote.SpanID())
+}
+
+func TestEventHandlerTraceParent(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	client := NewClient(WithEventAuthorization(false), WithTracerProvider(tp))
+
+	var handled trace.SpanContext
+	client.OnEvent(ProviderEventNewMessage, func(event *cloudevents.Event) (any, error) {
+		handled = trace.SpanContextFromContext(EventContext(event))
+		return nil, nil
+	})
+
+	event := cloudevents.NewEvent()
+	event.SetID("e1")
+	event.SetSource("provider.source/test/test")
+	event.SetType(ProviderEventNewMessage)
+	event.SetExtension("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_ = event.SetData(cloudevents.ApplicationJSON, &Message{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u1", Type: MessageTypeText, Text: "hello"})
+	body, _ := json.Marshal(&event)
+
+	w := httptest.NewRecorder()
+	client.EventHandler()(w, httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(string(body))))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	process := spans[0]
+	assert.Equal(t, ProviderEventNewMessage+" process", process.Name())
+	assert.Equal(t, trace.SpanKindConsumer, process.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", process.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", process.Parent().SpanID().String())
+	assert.True(t, process.Parent().IsRemote())
+	// 处理函数通过 EventContext 拿到的是处理事件的 span
+	assert.Equal(t, process.SpanContext().SpanID(), handled.SpanID())
+}
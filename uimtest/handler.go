@@ -0,0 +1,66 @@
+package uimtest
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+)
+
+// 录制收到的事件和返回的响应，录制的格式与 RecordingTransport 相同，可以用 RecordingTransport 回放
+type RecordingHandler struct {
+	lock         sync.Mutex
+	handler      http.Handler
+	interactions []*Interaction
+}
+
+// handler 一般为 EventHandler()
+func NewRecordingHandler(handler http.Handler) *RecordingHandler {
+	return &RecordingHandler{handler: handler}
+}
+
+func (h *RecordingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	interaction, err := newInteraction(r)
+	if err != nil {
+		// 不是 cloudevent 的请求不录制
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+	recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+	h.handler.ServeHTTP(recorder, r)
+
+	interaction.Status = recorder.status
+	interaction.ResponseBody = recorder.body.String()
+	h.lock.Lock()
+	h.interactions = append(h.interactions, interaction)
+	h.lock.Unlock()
+}
+
+// 已录制的请求和响应
+func (h *RecordingHandler) Interactions() []*Interaction {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return append([]*Interaction(nil), h.interactions...)
+}
+
+// 把录制的请求和响应写入文件
+func (h *RecordingHandler) Save(path string) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return saveInteractions(path, h.interactions)
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseRecorder) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,160 @@
+// issuer 模拟 UIM 的认证服务，可以在测试和本地调试时签发 token
+package issuer
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/http"
+	"time"
+
+	uim "github.com/uimkit/provider-go"
+)
+
+const keyId = "providertest"
+
+// 模拟 UIM 的认证服务，提供 openid-configuration、JWKS 和签发 access token 的接口
+// provider 使用 ServerOption 设置后，可以验证 Issuer 签发的 RS256 token
+type Issuer struct {
+	Audience string
+	server   *http.Server
+	baseURL  string
+	key      *rsa.PrivateKey
+}
+
+// 启动认证服务，使用完需要调用 Close
+func New(audience string) *Issuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic("issuer: failed to generate rsa key: " + err.Error())
+	}
+	issuer := &Issuer{
+		Audience: audience,
+		key:      key,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"issuer":                                issuer.URL(),
+			"jwks_uri":                              issuer.URL() + ".well-known/jwks.json",
+			"token_endpoint":                        issuer.TokenEndpoint(),
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/.well-known/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"use": "sig",
+				"alg": "RS256",
+				"kid": keyId,
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		payload := struct {
+			ClientId string `json:"client_id"`
+			Audience string `json:"audience"`
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		audience := payload.Audience
+		if audience == "" {
+			audience = issuer.Audience
+		}
+		writeJSON(w, map[string]any{
+			"access_token": issuer.Mint(issuer.claims(payload.ClientId, audience, time.Hour)),
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	})
+	// 不使用 httptest，避免依赖 testing 包
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic("issuer: failed to listen: " + err.Error())
+	}
+	issuer.baseURL = "http://" + listener.Addr().String()
+	issuer.server = &http.Server{Handler: mux}
+	go func() {
+		_ = issuer.server.Serve(listener)
+	}()
+	return issuer
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// 签发者地址，与 token 的 iss 一致
+func (issuer *Issuer) URL() string {
+	return issuer.baseURL + "/"
+}
+
+// 获取 access token 的地址，可以用于 uim.WithTokenEndpoint
+func (issuer *Issuer) TokenEndpoint() string {
+	return issuer.baseURL + "/oauth/token"
+}
+
+// provider 验证 token 的选项
+func (issuer *Issuer) ServerOption() uim.Option {
+	return uim.WithServer(issuer.URL(), issuer.Audience)
+}
+
+func (issuer *Issuer) claims(subject, audience string, expiresIn time.Duration) map[string]any {
+	now := time.Now()
+	return map[string]any{
+		"iss": issuer.URL(),
+		"sub": subject,
+		"aud": audience,
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"exp": now.Add(expiresIn).Unix(),
+	}
+}
+
+// 签发有效的 access token
+func (issuer *Issuer) Token() string {
+	return issuer.Mint(issuer.claims("providertest", issuer.Audience, time.Hour))
+}
+
+// 签发已过期的 access token
+func (issuer *Issuer) ExpiredToken() string {
+	return issuer.Mint(issuer.claims("providertest", issuer.Audience, -time.Hour))
+}
+
+// 签发 audience 不匹配的 access token
+func (issuer *Issuer) TokenForAudience(audience string) string {
+	return issuer.Mint(issuer.claims("providertest", audience, time.Hour))
+}
+
+// 使用指定的 claims 签发 RS256 token
+func (issuer *Issuer) Mint(claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": keyId,
+	})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		panic("issuer: failed to marshal claims: " + err.Error())
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, issuer.key, crypto.SHA256, digest[:])
+	if err != nil {
+		panic("issuer: failed to sign token: " + err.Error())
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func (issuer *Issuer) Close() {
+	issuer.server.Close()
+}
@@ -0,0 +1,234 @@
+// uimtest 提供测试 UIM 客户端和 provider 的工具
+package uimtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// 录制回放模式
+type Mode int
+
+const (
+	ModeReplay Mode = iota // 回放录制的响应，不发出请求
+	ModeRecord             // 发出请求并录制响应
+)
+
+// 一次录制的请求和响应
+type Interaction struct {
+	Method       string          `json:"method"`
+	Path         string          `json:"path"`
+	EventType    string          `json:"event_type"`
+	Data         json.RawMessage `json:"data,omitempty"` // 事件数据
+	Status       int             `json:"status"`
+	ResponseBody string          `json:"response_body,omitempty"`
+}
+
+// 录制回放的 http.RoundTripper，通过 uim.WithTransport 设置
+// 按事件类型和事件数据匹配请求，忽略随机生成的事件 ID，相同的请求按录制顺序回放
+type RecordingTransport struct {
+	lock         sync.Mutex
+	path         string
+	mode         Mode
+	transport    http.RoundTripper
+	interactions []*Interaction
+	replayed     map[*Interaction]bool
+}
+
+// path 是录制文件路径，transport 是录制时实际发出请求的 http.RoundTripper，为空时使用 http.DefaultTransport
+// 回放模式下会读取录制文件
+func NewRecordingTransport(path string, mode Mode, transport http.RoundTripper) (*RecordingTransport, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	t := &RecordingTransport{
+		path:      path,
+		mode:      mode,
+		transport: transport,
+		replayed:  make(map[*Interaction]bool),
+	}
+	if mode == ModeReplay {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(content, &t.interactions); err != nil {
+			return nil, fmt.Errorf("uimtest: invalid recording %s: %w", path, err)
+		}
+	}
+	return t, nil
+}
+
+// 已录制的请求和响应
+func (t *RecordingTransport) Interactions() []*Interaction {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return append([]*Interaction(nil), t.interactions...)
+}
+
+// 录制模式下把录制的请求和响应写入录制文件
+func (t *RecordingTransport) Save() error {
+	if t.mode != ModeRecord {
+		return nil
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return saveInteractions(t.path, t.interactions)
+}
+
+func saveInteractions(path string, interactions []*Interaction) error {
+	content, err := json.MarshalIndent(interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0644)
+}
+
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := newInteraction(req)
+	if err != nil {
+		return nil, err
+	}
+	if t.mode == ModeRecord {
+		return t.record(req, key)
+	}
+	return t.replay(req, key)
+}
+
+func (t *RecordingTransport) record(req *http.Request, interaction *Interaction) (*http.Response, error) {
+	res, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	interaction.Status = res.StatusCode
+	interaction.ResponseBody = string(redactAccessToken(body))
+	t.lock.Lock()
+	t.interactions = append(t.interactions, interaction)
+	t.lock.Unlock()
+	return res, nil
+}
+
+func (t *RecordingTransport) replay(req *http.Request, key *Interaction) (*http.Response, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var matched *Interaction
+	for _, interaction := range t.interactions {
+		if !interaction.matches(key) {
+			continue
+		}
+		matched = interaction
+		if !t.replayed[interaction] {
+			break
+		}
+	}
+	if matched == nil {
+		return nil, fmt.Errorf("uimtest: no recording for %s %s %s", key.Method, key.Path, key.EventType)
+	}
+	t.replayed[matched] = true
+
+	header := make(http.Header)
+	if json.Valid([]byte(matched.ResponseBody)) {
+		header.Set("Content-Type", "application/json")
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", matched.Status, http.StatusText(matched.Status)),
+		StatusCode:    matched.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(matched.ResponseBody)),
+		ContentLength: int64(len(matched.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+// 录制文件中不保存真实的 access token，回放时不会验证 token
+const recordedAccessToken = "recorded-access-token"
+
+func redactAccessToken(body []byte) []byte {
+	result := make(map[string]any)
+	if err := json.Unmarshal(body, &result); err != nil {
+		return body
+	}
+	if _, ok := result["access_token"]; !ok {
+		return body
+	}
+	result["access_token"] = recordedAccessToken
+	redacted, err := json.Marshal(result)
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+func (interaction *Interaction) matches(key *Interaction) bool {
+	return interaction.Method == key.Method &&
+		interaction.EventType == key.EventType &&
+		bytes.Equal(canonicalJSON(interaction.Data), canonicalJSON(key.Data))
+}
+
+// 从请求中读取事件类型和事件数据，请求的 body 会被重新设置，可以继续发送
+func newInteraction(req *http.Request) (*Interaction, error) {
+	interaction := &Interaction{
+		Method: req.Method,
+		Path:   req.URL.Path,
+	}
+	if req.Body == nil {
+		return interaction, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	event := struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &event); err != nil {
+			return nil, errors.New("uimtest: request body is not a cloudevent")
+		}
+	}
+	interaction.EventType = event.Type
+	interaction.Data = canonicalJSON(event.Data)
+	return interaction, nil
+}
+
+// 字段按名称排序的紧凑 JSON，用于比较事件数据
+func canonicalJSON(data json.RawMessage) json.RawMessage {
+	if len(data) == 0 {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return data
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return data
+	}
+	return canonical
+}
@@ -0,0 +1,102 @@
+package uimtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	uim "github.com/uimkit/provider-go"
+)
+
+func newClient(baseUrl string, transport http.RoundTripper) *uim.Client {
+	return uim.NewClient(
+		uim.WithAuthorization(false),
+		uim.WithEventSource("provider.source/test/test"),
+		uim.WithBaseUrl(baseUrl),
+		uim.WithTransport(transport),
+	)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":"InvalidEventData","message":"bad"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	path := filepath.Join(t.TempDir(), "testdata", "send_message.json")
+
+	recorder, err := NewRecordingTransport(path, ModeRecord, nil)
+	assert.Nil(t, err)
+	client := newClient(server.URL, recorder)
+	message := &uim.Message{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u1", Type: uim.MessageTypeText, Text: "hello"}
+	assert.Nil(t, client.SendEvent(uim.ProviderEventNewMessage, message))
+	assert.NotNil(t, client.SendEvent(uim.ProviderEventNewMessage, message))
+	assert.Nil(t, recorder.Save())
+	server.Close()
+	assert.Len(t, recorder.Interactions(), 2)
+
+	// 回放时事件 ID 不同，按事件类型和事件数据匹配，相同的请求按录制顺序回放
+	replayer, err := NewRecordingTransport(path, ModeReplay, nil)
+	assert.Nil(t, err)
+	client = newClient(server.URL, replayer)
+	assert.Nil(t, client.SendEvent(uim.ProviderEventNewMessage, message))
+	err = client.SendEvent(uim.ProviderEventNewMessage, message)
+	assert.Equal(t, "InvalidEventData", err.(*uim.ServerError).ErrorCode())
+
+	// 没有录制的请求
+	err = client.SendEvent(uim.ProviderEventNewMessage, &uim.Message{MessageId: "m2", Channel: "c1", Account: "a1", UserId: "u1", Type: uim.MessageTypeText, Text: "hello"})
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRecordAndReplayToken(t *testing.T) {
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth/token" {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "secret-token", "expires_in": 3600})
+			return
+		}
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+	}))
+	path := filepath.Join(t.TempDir(), "token.json")
+	newAuthorizedClient := func(transport http.RoundTripper) *uim.Client {
+		return uim.NewClient(
+			uim.WithClient("id", "secret", "uim"),
+			uim.WithTokenEndpoint(server.URL+"/oauth/token"),
+			uim.WithEventSource("provider.source/test/test"),
+			uim.WithBaseUrl(server.URL),
+			uim.WithTransport(transport),
+		)
+	}
+	message := &uim.Message{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u1", Type: uim.MessageTypeText, Text: "hello"}
+
+	recorder, err := NewRecordingTransport(path, ModeRecord, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, newAuthorizedClient(recorder).SendEvent(uim.ProviderEventNewMessage, message))
+	assert.Nil(t, recorder.Save())
+	server.Close()
+	assert.Equal(t, []string{"Bearer secret-token"}, authorizations)
+	assert.Len(t, recorder.Interactions(), 2)
+
+	// 录制文件不包含真实的 token
+	content, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.False(t, strings.Contains(string(content), "secret-token"))
+
+	// 回放时获取 token 的请求也不会发出
+	replayer, err := NewRecordingTransport(path, ModeReplay, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, newAuthorizedClient(replayer).SendEvent(uim.ProviderEventNewMessage, message))
+	assert.Len(t, authorizations, 1)
+}
@@ -2,6 +2,7 @@ package uim
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/url"
 	"reflect"
 	"strconv"
@@ -31,20 +32,51 @@ func initStructWithDefaultTag(bean any) {
 			continue
 		}
 		setter := reflect.ValueOf(bean).Elem().Field(i)
-		switch field.Type.String() {
-		case "int", "int32", "int64":
-			intValue, _ := strconv.ParseInt(defaultValue, 10, 64)
-			setter.SetInt(intValue)
-		case "time.Duration":
-			intValue, _ := strconv.ParseInt(defaultValue, 10, 64)
-			setter.SetInt(intValue)
-		case "string":
-			setter.SetString(defaultValue)
-		case "bool":
-			boolValue, _ := strconv.ParseBool(defaultValue)
-			setter.SetBool(boolValue)
+		_ = setFieldValue(setter, defaultValue)
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// 把字符串转换为字段的类型并设置，time.Duration 支持 Go 的 duration 格式和纳秒数
+func setFieldValue(setter reflect.Value, value string) error {
+	switch setter.Kind() {
+	case reflect.String:
+		setter.SetString(value)
+	case reflect.Bool:
+		boolValue, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		setter.SetBool(boolValue)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if setter.Type() == durationType {
+			if duration, err := time.ParseDuration(value); err == nil {
+				setter.SetInt(int64(duration))
+				return nil
+			}
+		}
+		intValue, err := strconv.ParseInt(value, 10, setter.Type().Bits())
+		if err != nil {
+			return err
+		}
+		setter.SetInt(intValue)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		uintValue, err := strconv.ParseUint(value, 10, setter.Type().Bits())
+		if err != nil {
+			return err
+		}
+		setter.SetUint(uintValue)
+	case reflect.Float32, reflect.Float64:
+		floatValue, err := strconv.ParseFloat(value, setter.Type().Bits())
+		if err != nil {
+			return err
 		}
+		setter.SetFloat(floatValue)
+	default:
+		return fmt.Errorf("unsupported type %s", setter.Type())
 	}
+	return nil
 }
 
 func toString(object any) string {
@@ -54,3 +86,24 @@ func toString(object any) string {
 	}
 	return string(byt)
 }
+
+// 从事件数据中按顺序查找第一个非空的字符串字段，支持嵌入结构体和指针
+func getStringField(data any, names ...string) string {
+	value := reflect.ValueOf(data)
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return ""
+	}
+	for _, name := range names {
+		field := value.FieldByName(name)
+		if field.IsValid() && field.Kind() == reflect.String && field.String() != "" {
+			return field.String()
+		}
+	}
+	return ""
+}
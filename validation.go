@@ -0,0 +1,997 @@
+package uim
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 可以校验的数据，SendEvent、Invoke 发送前和 CastEventHandler、CastCommandHandler 收到后会调用 Validate，
+// EventHandler 返回指令的结果前也会调用 Validate
+type Validator interface {
+	Validate() error
+}
+
+// 字段校验错误
+type FieldError struct {
+	Field   string // 字段的 JSON 路径，如：image.infos[0].url
+	Message string // 错误原因
+}
+
+func (err *FieldError) Error() string {
+	return err.Field + ": " + err.Message
+}
+
+// 数据校验错误，包含所有无效的字段
+type ValidationErrors []*FieldError
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// 校验数据，data 没有实现 Validator 时不校验
+func validateData(data any) error {
+	if v, ok := data.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// 发送前校验失败的错误
+func newInvalidEventDataClientError(err error) Error {
+	return NewClientError(InvalidEventDataErrorCode, fmt.Sprintf(InvalidEventDataErrorMessage, err.Error()), err)
+}
+
+// 收到的数据无效时返回给调用方的错误
+func newInvalidEventDataServerError(err error) Error {
+	return NewServerError(InvalidEventDataErrorStatus, InvalidEventDataErrorCode, fmt.Sprintf(InvalidEventDataErrorMessage, err.Error()), err)
+}
+
+// 模型内部的校验，嵌套的模型共用同一个 fieldValidator
+type validatable interface {
+	validate(v *fieldValidator)
+}
+
+// 收集字段校验错误
+type fieldValidator struct {
+	prefix string
+	errs   ValidationErrors
+}
+
+func validateModel(model validatable) error {
+	v := &fieldValidator{}
+	model.validate(v)
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func (v *fieldValidator) invalid(field, format string, args ...any) {
+	v.errs = append(v.errs, &FieldError{Field: v.prefix + field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *fieldValidator) required(field, value string) {
+	if value == "" {
+		v.invalid(field, "is required")
+	}
+}
+
+// 校验嵌套的模型，字段名加上前缀
+func (v *fieldValidator) nested(field string, model validatable) {
+	prefix := v.prefix
+	v.prefix = prefix + field + "."
+	model.validate(v)
+	v.prefix = prefix
+}
+
+func (v *fieldValidator) oneOf(field string, value, min, max int) {
+	if value < min || value > max {
+		v.invalid(field, "must be between %d and %d", min, max)
+	}
+}
+
+func (query *CursorQuery) Validate() error { return validateModel(query) }
+
+func (query *CursorQuery) validate(v *fieldValidator) {
+	if query.Limit < 0 {
+		v.invalid("limit", "must not be negative")
+	}
+	switch query.Direction {
+	case "", CursorDirectionBefore, CursorDirectionAfter:
+	default:
+		v.invalid("direction", "must be %s or %s", CursorDirectionBefore, CursorDirectionAfter)
+	}
+}
+
+func (extra *CursorExtra) Validate() error { return validateModel(extra) }
+
+func (extra *CursorExtra) validate(v *fieldValidator) {
+	if extra.Limit < 0 {
+		v.invalid("limit", "must not be negative")
+	}
+}
+
+// 结果集中的数据实现了 validatable 时逐条校验
+func validateCursorPage[T any](v *fieldValidator, page *CursorPage[T]) {
+	v.nested("extra", &page.Extra)
+	for i, item := range page.Items {
+		field := fmt.Sprintf("items[%d].item", i)
+		value := reflect.ValueOf(item.Item)
+		if !value.IsValid() || (value.Kind() == reflect.Pointer && value.IsNil()) {
+			v.invalid(field, "is required")
+		} else if model, ok := any(item.Item).(validatable); ok {
+			v.nested(field, model)
+		}
+	}
+}
+
+func (user *IMUser) Validate() error { return validateModel(user) }
+
+func (user *IMUser) validate(v *fieldValidator) {
+	v.required("user_id", user.UserId)
+	v.oneOf("gender", int(user.Gender), int(GenderUnknown), int(GenderFemale))
+}
+
+func (user *IMUserUpdate) Validate() error { return validateModel(user) }
+
+func (user *IMUserUpdate) validate(v *fieldValidator) {
+	v.required("user_id", user.UserId)
+	if user.Gender != nil {
+		v.oneOf("gender", int(*user.Gender), int(GenderUnknown), int(GenderFemale))
+	}
+}
+
+func (account *IMAccount) Validate() error { return validateModel(account) }
+
+func (account *IMAccount) validate(v *fieldValidator) {
+	account.IMUser.validate(v)
+	v.oneOf("presence", int(account.Presence), int(PresenceInactive), int(PresenceBanned))
+}
+
+func (account *IMAccountUpdate) Validate() error { return validateModel(account) }
+
+func (account *IMAccountUpdate) validate(v *fieldValidator) {
+	account.IMUserUpdate.validate(v)
+	if account.Presence != nil {
+		v.oneOf("presence", int(*account.Presence), int(PresenceInactive), int(PresenceBanned))
+	}
+}
+
+func (contact *Contact) Validate() error { return validateModel(contact) }
+
+func (contact *Contact) validate(v *fieldValidator) {
+	contact.IMUser.validate(v)
+	v.required("account", contact.Account)
+}
+
+func (follower *Follower) Validate() error { return validateModel((*Contact)(follower)) }
+
+func (following *Following) Validate() error { return validateModel((*Contact)(following)) }
+
+func (apply *FriendApply) Validate() error { return validateModel(apply) }
+
+func (apply *FriendApply) validate(v *fieldValidator) {
+	apply.IMUser.validate(v)
+	v.required("id", apply.ID)
+	v.required("account", apply.Account)
+}
+
+func (info *ImageInfo) Validate() error { return validateModel(info) }
+
+func (info *ImageInfo) validate(v *fieldValidator) {
+	v.required("url", info.URL)
+}
+
+func (image *ImageAttachment) Validate() error { return validateModel(image) }
+
+func (image *ImageAttachment) validate(v *fieldValidator) {
+	if len(image.Infos) == 0 {
+		v.invalid("infos", "is required")
+	}
+	for i, info := range image.Infos {
+		if info == nil {
+			v.invalid(fmt.Sprintf("infos[%d]", i), "is required")
+		} else {
+			v.nested(fmt.Sprintf("infos[%d]", i), info)
+		}
+	}
+}
+
+func (audio *AudioAttachment) Validate() error { return validateModel(audio) }
+
+func (audio *AudioAttachment) validate(v *fieldValidator) {
+	v.required("url", audio.URL)
+}
+
+func (video *VideoAttachment) Validate() error { return validateModel(video) }
+
+func (video *VideoAttachment) validate(v *fieldValidator) {
+	v.required("url", video.URL)
+}
+
+func (miniProgram *MiniProgramAttachment) Validate() error { return validateModel(miniProgram) }
+
+func (miniProgram *MiniProgramAttachment) validate(v *fieldValidator) {
+	v.required("content", miniProgram.Content)
+}
+
+func (file *FileAttachment) Validate() error { return validateModel(file) }
+
+func (file *FileAttachment) validate(v *fieldValidator) {
+	v.required("url", file.URL)
+}
+
+func (link *LinkAttachment) Validate() error { return validateModel(link) }
+
+func (link *LinkAttachment) validate(v *fieldValidator) {
+	v.required("url", link.URL)
+}
+
+func (record *ChatRecordAttachment) Validate() error { return validateModel(record) }
+
+func (record *ChatRecordAttachment) validate(v *fieldValidator) {
+	if len(record.Messages) == 0 {
+		v.invalid("messages", "is required")
+	}
+	for i, message := range record.Messages {
+		if message == nil {
+			v.invalid(fmt.Sprintf("messages[%d]", i), "is required")
+		} else {
+			v.nested(fmt.Sprintf("messages[%d]", i), message)
+		}
+	}
+}
+
+func (message *ChatRecordMessage) Validate() error { return validateModel(message) }
+
+func (message *ChatRecordMessage) validate(v *fieldValidator) {
+	v.required("user_id", message.UserId)
+	validateMessageContent(v, message.Type, message.Text, messageAttachments{
+		Image:       message.Image,
+		Audio:       message.Audio,
+		Video:       message.Video,
+		MiniProgram: message.MiniProgram,
+		File:        message.File,
+		Link:        message.Link,
+		ChatRecord:  message.ChatRecord,
+	})
+}
+
+// 消息附件
+type messageAttachments struct {
+	Image       *ImageAttachment
+	Audio       *AudioAttachment
+	Video       *VideoAttachment
+	MiniProgram *MiniProgramAttachment
+	File        *FileAttachment
+	Link        *LinkAttachment
+	ChatRecord  *ChatRecordAttachment
+}
+
+func isMessageType(messageType MessageType) bool {
+	switch messageType {
+	case MessageTypeText, MessageTypeImage, MessageTypeAudio, MessageTypeVideo, MessageTypeMiniProgram,
+		MessageTypeFile, MessageTypeLink, MessageTypeLocation, MessageTypeChatRecord:
+		return true
+	}
+	return false
+}
+
+// 消息类型和附件需要一致：对应类型的附件必须存在，其他附件必须为空，视频消息可以带图片作为封面
+func validateMessageContent(v *fieldValidator, messageType MessageType, text string, attachments messageAttachments) {
+	switch {
+	case messageType == "":
+		v.invalid("type", "is required")
+	case !isMessageType(messageType):
+		v.invalid("type", "unsupported message type %q", messageType)
+	case messageType == MessageTypeText:
+		v.required("text", text)
+	}
+	fields := []struct {
+		field       string
+		messageType MessageType
+		set         bool
+		model       validatable
+	}{
+		{"image", MessageTypeImage, attachments.Image != nil, attachments.Image},
+		{"audio", MessageTypeAudio, attachments.Audio != nil, attachments.Audio},
+		{"video", MessageTypeVideo, attachments.Video != nil, attachments.Video},
+		{"miniprogram", MessageTypeMiniProgram, attachments.MiniProgram != nil, attachments.MiniProgram},
+		{"file", MessageTypeFile, attachments.File != nil, attachments.File},
+		{"link", MessageTypeLink, attachments.Link != nil, attachments.Link},
+		{"chat_record", MessageTypeChatRecord, attachments.ChatRecord != nil, attachments.ChatRecord},
+	}
+	for _, f := range fields {
+		switch {
+		case f.set && f.messageType != messageType && !(f.messageType == MessageTypeImage && messageType == MessageTypeVideo):
+			v.invalid(f.field, "must be empty for %s message", messageType)
+		case f.set:
+			v.nested(f.field, f.model)
+		case f.messageType == messageType:
+			v.invalid(f.field, "is required for %s message", messageType)
+		}
+	}
+}
+
+func (message *Message) Validate() error { return validateModel(message) }
+
+func (message *Message) validate(v *fieldValidator) {
+	v.required("message_id", message.MessageId)
+	v.required("channel", message.Channel)
+	v.required("account", message.Account)
+	v.required("user_id", message.UserId)
+	// 撤回的消息可能已经清空了内容
+	if !message.Revoked {
+		validateMessageContent(v, message.Type, message.Text, messageAttachments{
+			Image:       message.Image,
+			Audio:       message.Audio,
+			Video:       message.Video,
+			MiniProgram: message.MiniProgram,
+			File:        message.File,
+			Link:        message.Link,
+			ChatRecord:  message.ChatRecord,
+		})
+	}
+	validateReference(v, message.Reference, message.Channel)
+	validateReactions(v, message.Reactions)
+	validateMessageStatus(v, message.Status, message.FailedReason)
+	if message.Reference != nil && message.Reference.MessageId == message.MessageId && message.MessageId != "" {
+		v.invalid("reference.message_id", "must not be the message itself")
+	}
+}
+
+func (user *MessageMentionedUser) Validate() error { return validateModel(user) }
+
+func (user *MessageMentionedUser) validate(v *fieldValidator) {
+	v.required("user_id", user.UserId)
+	if user.StartAt < 0 {
+		v.invalid("start_at", "must not be negative")
+	}
+	if user.EndAt <= user.StartAt {
+		v.invalid("end_at", "must be greater than start_at")
+	}
+}
+
+func (req *SendMessageRequest) Validate() error { return validateModel(req) }
+
+func (req *SendMessageRequest) validate(v *fieldValidator) {
+	v.required("account", req.Account)
+	v.required("channel", req.Channel)
+	switch req.ConversationType {
+	case "", ConversationTypePrivate, ConversationTypeGroup, ConversationTypeDiscussion,
+		ConversationTypeSystem, ConversationTypeCustomerService:
+	default:
+		v.invalid("conversation_type", "unsupported conversation type %q", req.ConversationType)
+	}
+	validateMessageContent(v, req.Type, req.Text, messageAttachments{
+		Image:       req.Image,
+		Audio:       req.Audio,
+		Video:       req.Video,
+		MiniProgram: req.MiniProgram,
+		File:        req.File,
+		Link:        req.Link,
+		ChatRecord:  req.ChatRecord,
+	})
+	if req.Seq < 0 {
+		v.invalid("seq", "must not be negative")
+	}
+	validateMentionedUsers(v, req.MentionedUsers)
+	validateReference(v, req.Reference, req.Channel)
+}
+
+func (resp *SendMessageResponse) Validate() error { return validateModel(resp) }
+
+func (resp *SendMessageResponse) validate(v *fieldValidator) {
+	resp.Message.validate(v)
+}
+
+// 引用的消息必须和消息在同一个收发地址
+func validateReference(v *fieldValidator, reference *MessageReference, channel string) {
+	if reference == nil {
+		return
+	}
+	v.nested("reference", reference)
+	if reference.Channel != "" && reference.Channel != channel {
+		v.invalid("reference.channel", "must be the same as channel")
+	}
+}
+
+func (reference *MessageReference) Validate() error { return validateModel(reference) }
+
+func (reference *MessageReference) validate(v *fieldValidator) {
+	v.required("message_id", reference.MessageId)
+	if reference.Type != "" && !isMessageType(reference.Type) {
+		v.invalid("type", "unsupported message type %q", reference.Type)
+	}
+}
+
+func validateMentionedUsers(v *fieldValidator, users []*MessageMentionedUser) {
+	for i, user := range users {
+		if user == nil {
+			v.invalid(fmt.Sprintf("mentioned_users[%d]", i), "is required")
+		} else if user.UserId != MessageMentionedAll {
+			v.nested(fmt.Sprintf("mentioned_users[%d]", i), user)
+		}
+	}
+}
+
+func (update *MessageUpdate) Validate() error { return validateModel(update) }
+
+func (update *MessageUpdate) validate(v *fieldValidator) {
+	v.required("message_id", update.MessageId)
+	for i, edit := range update.EditHistory {
+		if edit == nil {
+			v.invalid(fmt.Sprintf("edit_history[%d]", i), "is required")
+		} else {
+			v.nested(fmt.Sprintf("edit_history[%d]", i), edit)
+		}
+	}
+	validateReactions(v, update.Reactions)
+}
+
+func isMessageStatus(status MessageStatus) bool {
+	switch status {
+	case MessageStatusSending, MessageStatusSent, MessageStatusDelivered, MessageStatusRead, MessageStatusFailed:
+		return true
+	}
+	return false
+}
+
+// 发送失败时需要原因，其他状态不能有原因
+func validateMessageStatus(v *fieldValidator, status MessageStatus, failedReason string) {
+	if status != "" && !isMessageStatus(status) {
+		v.invalid("status", "unsupported message status %q", status)
+	}
+	if status == MessageStatusFailed {
+		v.required("failed_reason", failedReason)
+	} else if failedReason != "" {
+		v.invalid("failed_reason", "must be empty for %s status", status)
+	}
+}
+
+func (receipt *MessageReceipt) Validate() error { return validateModel(receipt) }
+
+func (receipt *MessageReceipt) validate(v *fieldValidator) {
+	v.required("user_id", receipt.UserId)
+	switch {
+	case receipt.Status == "":
+		v.invalid("status", "is required")
+	case !isMessageStatus(receipt.Status):
+		v.invalid("status", "unsupported message status %q", receipt.Status)
+	}
+}
+
+func (change *MessageStatusChange) Validate() error { return validateModel(change) }
+
+func (change *MessageStatusChange) validate(v *fieldValidator) {
+	v.required("message_id", change.MessageId)
+	v.required("channel", change.Channel)
+	v.required("account", change.Account)
+	if change.Status == "" && len(change.Receipts) == 0 {
+		v.invalid("status", "is required without receipts")
+	}
+	validateMessageStatus(v, change.Status, change.FailedReason)
+	for i, receipt := range change.Receipts {
+		if receipt == nil {
+			v.invalid(fmt.Sprintf("receipts[%d]", i), "is required")
+		} else {
+			v.nested(fmt.Sprintf("receipts[%d]", i), receipt)
+		}
+	}
+}
+
+func (req *MarkReadRequest) Validate() error { return validateModel(req) }
+
+func (req *MarkReadRequest) validate(v *fieldValidator) {
+	v.required("account", req.Account)
+	v.required("channel", req.Channel)
+}
+
+func (reaction *Reaction) Validate() error { return validateModel(reaction) }
+
+func (reaction *Reaction) validate(v *fieldValidator) {
+	v.required("message_id", reaction.MessageId)
+	v.required("channel", reaction.Channel)
+	v.required("account", reaction.Account)
+	v.required("user_id", reaction.UserId)
+	v.required("emoji", reaction.Emoji)
+}
+
+func (count *ReactionCount) Validate() error { return validateModel(count) }
+
+func (count *ReactionCount) validate(v *fieldValidator) {
+	v.required("emoji", count.Emoji)
+	if count.Count < 0 {
+		v.invalid("count", "must not be negative")
+	}
+}
+
+func validateReactions(v *fieldValidator, reactions []*ReactionCount) {
+	for i, reaction := range reactions {
+		if reaction == nil {
+			v.invalid(fmt.Sprintf("reactions[%d]", i), "is required")
+		} else {
+			v.nested(fmt.Sprintf("reactions[%d]", i), reaction)
+		}
+	}
+}
+
+func (req *ReactMessageRequest) Validate() error { return validateModel(req) }
+
+func (req *ReactMessageRequest) validate(v *fieldValidator) {
+	v.required("account", req.Account)
+	v.required("channel", req.Channel)
+	v.required("message_id", req.MessageId)
+	v.required("emoji", req.Emoji)
+}
+
+func (resp *ReactMessageResponse) Validate() error { return validateModel(resp) }
+
+func (resp *ReactMessageResponse) validate(v *fieldValidator) {
+	validateReactions(v, resp.Reactions)
+}
+
+func (edit *MessageEdit) Validate() error { return validateModel(edit) }
+
+func (edit *MessageEdit) validate(v *fieldValidator) {
+	if edit.EditedAt == nil {
+		v.invalid("edited_at", "is required")
+	}
+}
+
+func (req *RevokeMessageRequest) Validate() error { return validateModel(req) }
+
+func (req *RevokeMessageRequest) validate(v *fieldValidator) {
+	v.required("account", req.Account)
+	v.required("channel", req.Channel)
+	v.required("message_id", req.MessageId)
+}
+
+func (resp *RevokeMessageResponse) Validate() error { return validateModel(resp) }
+
+func (resp *RevokeMessageResponse) validate(v *fieldValidator) {
+	resp.Message.validate(v)
+}
+
+func (req *EditMessageRequest) Validate() error { return validateModel(req) }
+
+func (req *EditMessageRequest) validate(v *fieldValidator) {
+	v.required("account", req.Account)
+	v.required("channel", req.Channel)
+	v.required("message_id", req.MessageId)
+	v.required("text", req.Text)
+	validateMentionedUsers(v, req.MentionedUsers)
+}
+
+func (resp *EditMessageResponse) Validate() error { return validateModel(resp) }
+
+func (resp *EditMessageResponse) validate(v *fieldValidator) {
+	resp.Message.validate(v)
+}
+
+func (req *ForwardMessageRequest) Validate() error { return validateModel(req) }
+
+func (req *ForwardMessageRequest) validate(v *fieldValidator) {
+	v.required("account", req.Account)
+	v.required("channel", req.Channel)
+	v.required("to_channel", req.ToChannel)
+	if len(req.MessageIds) == 0 {
+		v.invalid("message_ids", "is required")
+	}
+	for i, messageId := range req.MessageIds {
+		v.required(fmt.Sprintf("message_ids[%d]", i), messageId)
+	}
+	if req.Title != "" && !req.Merge {
+		v.invalid("title", "must be empty when not merging")
+	}
+}
+
+func (resp *ForwardMessageResponse) Validate() error { return validateModel(resp) }
+
+func (resp *ForwardMessageResponse) validate(v *fieldValidator) {
+	for i, message := range resp.Messages {
+		if message == nil {
+			v.invalid(fmt.Sprintf("messages[%d]", i), "is required")
+		} else {
+			v.nested(fmt.Sprintf("messages[%d]", i), message)
+		}
+	}
+}
+
+func (req *DeleteMessageRequest) Validate() error { return validateModel(req) }
+
+func (req *DeleteMessageRequest) validate(v *fieldValidator) {
+	v.required("account", req.Account)
+	v.required("channel", req.Channel)
+	v.required("message_id", req.MessageId)
+}
+
+func (req *GetChannelInfoRequest) Validate() error { return validateModel(req) }
+
+func (req *GetChannelInfoRequest) validate(v *fieldValidator) {
+	v.required("channel", req.Channel)
+}
+
+func (resp *GetChannelInfoResponse) Validate() error { return validateModel(resp) }
+
+func (resp *GetChannelInfoResponse) validate(v *fieldValidator) {
+	if resp.Group != nil {
+		v.nested("group", resp.Group)
+	}
+	if resp.User != nil {
+		v.nested("user", resp.User)
+	}
+}
+
+func (group *Group) Validate() error { return validateModel(group) }
+
+func (group *Group) validate(v *fieldValidator) {
+	v.required("group_id", group.GroupId)
+	v.required("account", group.Account)
+	if group.Owner != nil {
+		v.nested("owner", group.Owner)
+	}
+}
+
+func (update *GroupUpdate) Validate() error { return validateModel(update) }
+
+func (update *GroupUpdate) validate(v *fieldValidator) {
+	v.required("group_id", update.GroupId)
+	if update.Owner != nil {
+		v.nested("owner", update.Owner)
+	}
+}
+
+func (member *GroupMember) Validate() error { return validateModel(member) }
+
+func (member *GroupMember) validate(v *fieldValidator) {
+	member.IMUser.validate(v)
+	v.required("group_id", member.GroupId)
+	v.oneOf("role", int(member.Role), int(GroupMemberRoleMember), int(GroupMemberRoleOwner))
+}
+
+func (update *GroupMemberUpdate) Validate() error { return validateModel(update) }
+
+// 群成员变更可以只指定 member_id，不需要 user_id
+func (update *GroupMemberUpdate) validate(v *fieldValidator) {
+	v.required("group_id", update.GroupId)
+	if update.MemberId == "" && update.UserId == "" {
+		v.invalid("member_id", "member_id or user_id is required")
+	}
+	if update.Gender != nil {
+		v.oneOf("gender", int(*update.Gender), int(GenderUnknown), int(GenderFemale))
+	}
+	if update.Role != nil {
+		v.oneOf("role", int(*update.Role), int(GroupMemberRoleMember), int(GroupMemberRoleOwner))
+	}
+}
+
+func (invitation *GroupInvitation) Validate() error { return validateModel(invitation) }
+
+func (invitation *GroupInvitation) validate(v *fieldValidator) {
+	v.required("id", invitation.ID)
+	v.required("user_id", invitation.UserId)
+	v.required("group_id", invitation.GroupId)
+	if invitation.Inviter != nil {
+		v.nested("inviter", invitation.Inviter)
+	}
+}
+
+func (apply *GroupApply) Validate() error { return validateModel(apply) }
+
+func (apply *GroupApply) validate(v *fieldValidator) {
+	v.required("id", apply.ID)
+	v.required("user_id", apply.UserId)
+	v.required("group_id", apply.GroupId)
+	if apply.ApplyUser != nil {
+		v.nested("apply_user", apply.ApplyUser)
+	}
+}
+
+func (req *SetGroupMuteRequest) Validate() error { return validateModel(req) }
+
+func (req *SetGroupMuteRequest) validate(v *fieldValidator) {
+	v.required("user_id", req.UserId)
+	v.required("group_id", req.GroupId)
+}
+
+func (req *AddContactRequest) Validate() error { return validateModel(req) }
+
+func (req *AddContactRequest) validate(v *fieldValidator) {
+	v.required("user_id", req.UserId)
+	v.required("contact", req.Contact)
+}
+
+func (req *AcceptFriendApplyRequest) Validate() error { return validateModel(req) }
+
+func (req *AcceptFriendApplyRequest) validate(v *fieldValidator) {
+	v.required("apply_id", req.ApplyId)
+	v.required("user_id", req.UserId)
+}
+
+// 自定义数据的值需要和类型一致，值为空时不校验
+func validateMetafieldValue(v *fieldValidator, valueType MetafieldValueType, value any) {
+	var ok bool
+	switch valueType {
+	case "":
+		v.invalid("type", "is required")
+		return
+	case MetafieldValueTypeInteger:
+		ok = isInteger(value)
+	case MetafieldValueTypeString:
+		_, ok = value.(string)
+	case MetafieldValueTypeBoolean:
+		_, ok = value.(bool)
+	case MetafieldValueTypeDateTime:
+		switch t := value.(type) {
+		case time.Time, *time.Time:
+			ok = true
+		case string:
+			_, err := time.Parse(time.RFC3339, t)
+			ok = err == nil
+		}
+	case MetafieldValueTypeJsonArray:
+		kind := reflect.ValueOf(value).Kind()
+		ok = kind == reflect.Slice || kind == reflect.Array
+	case MetafieldValueTypeJsonMap:
+		ok = reflect.ValueOf(value).Kind() == reflect.Map
+	case MetafieldValueTypeDecimal:
+		ok = isDecimal(value)
+	default:
+		v.invalid("type", "unsupported metafield type %q", valueType)
+		return
+	}
+	if value != nil && !ok {
+		v.invalid("value", "must be %s", valueType)
+	}
+}
+
+// 整数，JSON 解码后的数字是 float64 或 json.Number
+func isInteger(value any) bool {
+	switch n := value.(type) {
+	case json.Number:
+		_, err := n.Int64()
+		return err == nil
+	case float64:
+		return n == math.Trunc(n) && !math.IsInf(n, 0)
+	case float32:
+		return float64(n) == math.Trunc(float64(n)) && !math.IsInf(float64(n), 0)
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// 小数，可以是数字或数字字符串
+func isDecimal(value any) bool {
+	switch n := value.(type) {
+	case json.Number:
+		_, err := n.Float64()
+		return err == nil
+	case string:
+		_, err := strconv.ParseFloat(n, 64)
+		return err == nil
+	case float64, float32:
+		return true
+	}
+	return isInteger(value)
+}
+
+func (metafield *Metafield) Validate() error { return validateModel(metafield) }
+
+func (metafield *Metafield) validate(v *fieldValidator) {
+	v.required("resource", metafield.Resource)
+	v.required("resource_id", metafield.ResourceId)
+	v.required("key", metafield.Key)
+	if metafield.Value == nil {
+		v.invalid("value", "is required")
+	}
+	validateMetafieldValue(v, metafield.Type, metafield.Value)
+}
+
+func (update *MetafieldUpdate) Validate() error { return validateModel(update) }
+
+func (update *MetafieldUpdate) validate(v *fieldValidator) {
+	v.required("resource", update.Resource)
+	v.required("resource_id", update.ResourceId)
+	v.required("key", update.Key)
+	validateMetafieldValue(v, update.Type, update.Value)
+}
+
+func (req *GetMetafieldRequest) Validate() error { return validateModel(req) }
+
+func (req *GetMetafieldRequest) validate(v *fieldValidator) {
+	v.required("resource", req.Resource)
+	v.required("resource_id", req.ResourceId)
+	v.required("key", req.Key)
+}
+
+func (resp *GetMetafieldResponse) Validate() error { return validateModel(resp) }
+
+func (resp *GetMetafieldResponse) validate(v *fieldValidator) {
+	resp.Metafield.validate(v)
+}
+
+func (comment *Comment) Validate() error { return validateModel(comment) }
+
+func (comment *Comment) validate(v *fieldValidator) {
+	v.required("comment_id", comment.CommentId)
+	if comment.User != nil {
+		v.nested("user", comment.User)
+	}
+	if comment.ReplyToUser != nil {
+		v.nested("reply_to_user", comment.ReplyToUser)
+	}
+}
+
+func (like *Like) Validate() error { return validateModel(like) }
+
+func (like *Like) validate(v *fieldValidator) {
+	v.required("like_id", like.LikeId)
+	if like.User != nil {
+		v.nested("user", like.User)
+	}
+}
+
+// 动态附件
+type momentAttachments struct {
+	Images      []*ImageAttachment
+	Video       *VideoAttachment
+	MiniProgram *MiniProgramAttachment
+	Link        *LinkAttachment
+}
+
+// 动态类型和附件需要一致：对应类型的附件必须存在，其他附件必须为空，视频动态可以带图片作为封面
+func validateMomentContent(v *fieldValidator, momentType MomentType, text string, attachments momentAttachments) {
+	switch momentType {
+	case "":
+		v.invalid("type", "is required")
+	case MomentTypeText:
+		v.required("text", text)
+	case MomentTypeImage, MomentTypeVideo, MomentTypeMiniProgram, MomentTypeLink:
+	default:
+		v.invalid("type", "unsupported moment type %q", momentType)
+	}
+	switch {
+	case len(attachments.Images) > 0 && momentType != MomentTypeImage && momentType != MomentTypeVideo:
+		v.invalid("images", "must be empty for %s moment", momentType)
+	case len(attachments.Images) == 0 && momentType == MomentTypeImage:
+		v.invalid("images", "is required for %s moment", momentType)
+	}
+	for i, image := range attachments.Images {
+		if image == nil {
+			v.invalid(fmt.Sprintf("images[%d]", i), "is required")
+		} else {
+			v.nested(fmt.Sprintf("images[%d]", i), image)
+		}
+	}
+	fields := []struct {
+		field      string
+		momentType MomentType
+		set        bool
+		model      validatable
+	}{
+		{"video", MomentTypeVideo, attachments.Video != nil, attachments.Video},
+		{"miniprogram", MomentTypeMiniProgram, attachments.MiniProgram != nil, attachments.MiniProgram},
+		{"link", MomentTypeLink, attachments.Link != nil, attachments.Link},
+	}
+	for _, f := range fields {
+		switch {
+		case f.set && f.momentType != momentType:
+			v.invalid(f.field, "must be empty for %s moment", momentType)
+		case f.set:
+			v.nested(f.field, f.model)
+		case f.momentType == momentType:
+			v.invalid(f.field, "is required for %s moment", momentType)
+		}
+	}
+}
+
+func (moment *Moment) Validate() error { return validateModel(moment) }
+
+func (moment *Moment) validate(v *fieldValidator) {
+	v.required("moment_id", moment.MomentId)
+	v.required("account", moment.Account)
+	if moment.User != nil {
+		v.nested("user", moment.User)
+	}
+	validateMomentContent(v, moment.Type, moment.Text, momentAttachments{
+		Images:      moment.Images,
+		Video:       moment.Video,
+		MiniProgram: moment.MiniProgram,
+		Link:        moment.Link,
+	})
+}
+
+func (req *GetMomentListRequest) Validate() error { return validateModel(req) }
+
+func (req *GetMomentListRequest) validate(v *fieldValidator) {
+	req.CursorQuery.validate(v)
+	v.required("account", req.Account)
+}
+
+func (resp *GetMomentListResponse) Validate() error { return validateModel(resp) }
+
+func (resp *GetMomentListResponse) validate(v *fieldValidator) {
+	validateCursorPage(v, &resp.CursorPage)
+}
+
+func (req *PublishMomentRequest) Validate() error { return validateModel(req) }
+
+func (req *PublishMomentRequest) validate(v *fieldValidator) {
+	v.required("account", req.Account)
+	validateMomentContent(v, req.Type, req.Text, momentAttachments{
+		Images:      req.Images,
+		Video:       req.Video,
+		MiniProgram: req.MiniProgram,
+		Link:        req.Link,
+	})
+	switch req.Privacy {
+	case MomentPrivacyPublic, MomentPrivacyPrivate:
+		if len(req.PrivacyUsers) > 0 {
+			v.invalid("privacy_users", "must be empty for %s privacy", req.Privacy)
+		}
+	case MomentPrivacyVisibleForUsers, MomentPrivacyInvisibleForUsers:
+		if len(req.PrivacyUsers) == 0 {
+			v.invalid("privacy_users", "is required for %s privacy", req.Privacy)
+		}
+	case "":
+		v.invalid("privacy", "is required")
+	default:
+		v.invalid("privacy", "unsupported moment privacy %q", req.Privacy)
+	}
+	for i, user := range req.PrivacyUsers {
+		if user == "" {
+			v.invalid(fmt.Sprintf("privacy_users[%d]", i), "is required")
+		}
+	}
+}
+
+func (resp *PublishMomentResponse) Validate() error { return validateModel(resp) }
+
+func (resp *PublishMomentResponse) validate(v *fieldValidator) {
+	resp.Moment.validate(v)
+}
+
+func (capabilities *Capabilities) Validate() error { return validateModel(capabilities) }
+
+func (capabilities *Capabilities) validate(v *fieldValidator) {
+	for i, messageType := range capabilities.MessageTypes {
+		if !isMessageType(messageType) {
+			v.invalid(fmt.Sprintf("message_types[%d]", i), "unsupported message type %q", messageType)
+		}
+	}
+	messageTypes := make([]string, 0, len(capabilities.MaxAttachmentSizes))
+	for messageType := range capabilities.MaxAttachmentSizes {
+		messageTypes = append(messageTypes, string(messageType))
+	}
+	sort.Strings(messageTypes)
+	for _, messageType := range messageTypes {
+		field := "max_attachment_sizes." + messageType
+		if !isMessageType(MessageType(messageType)) {
+			v.invalid(field, "unsupported message type %q", messageType)
+		}
+		if capabilities.MaxAttachmentSizes[MessageType(messageType)] < 0 {
+			v.invalid(field, "must not be negative")
+		}
+	}
+}
+
+func (resp *GetCapabilitiesResponse) Validate() error { return validateModel(resp) }
+
+func (resp *GetCapabilitiesResponse) validate(v *fieldValidator) {
+	resp.Capabilities.validate(v)
+}
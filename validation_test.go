@@ -0,0 +1,239 @@
+package uim
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func fieldErrors(err error) []string {
+	var fields []string
+	if errs, ok := err.(ValidationErrors); ok {
+		for _, e := range errs {
+			fields = append(fields, e.Field)
+		}
+	}
+	return fields
+}
+
+func TestValidateModels(t *testing.T) {
+	assert.Equal(t, []string{"user_id"}, fieldErrors((&IMAccount{}).Validate()))
+	assert.Equal(t, []string{"user_id", "presence"}, fieldErrors((&IMAccount{Presence: 10}).Validate()))
+	assert.Equal(t, []string{"user_id"}, fieldErrors((&IMAccountUpdate{}).Validate()))
+	assert.Equal(t, []string{"account"}, fieldErrors((*Follower)(&Contact{IMUser: IMUser{UserId: "u1"}}).Validate()))
+
+	message := &Message{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u1", Type: MessageTypeText, Text: "hello"}
+	assert.Nil(t, message.Validate())
+	message.Type = MessageTypeImage
+	message.Link = &LinkAttachment{}
+	assert.Equal(t, []string{"image", "link"}, fieldErrors(message.Validate()))
+	message.Link = nil
+	message.Image = &ImageAttachment{Infos: []*ImageInfo{{}}}
+	assert.Equal(t, []string{"image.infos[0].url"}, fieldErrors(message.Validate()))
+
+	video := &SendMessageRequest{
+		Account: "a1",
+		Channel: "c1",
+		Type:    MessageTypeVideo,
+		Video:   &VideoAttachment{URL: "https://video.url"},
+		Image:   &ImageAttachment{Infos: []*ImageInfo{{URL: "https://image.url"}}},
+	}
+	assert.Nil(t, video.Validate())
+	video.MentionedUsers = []*MessageMentionedUser{{UserId: MessageMentionedAll}, {UserId: "u2", StartAt: 3, EndAt: 3}}
+	assert.Equal(t, []string{"mentioned_users[1].end_at"}, fieldErrors(video.Validate()))
+
+	moment := &PublishMomentRequest{Account: "a1", Type: MomentTypeText, Text: "hello", Privacy: MomentPrivacyVisibleForUsers}
+	assert.Equal(t, []string{"privacy_users"}, fieldErrors(moment.Validate()))
+	moment.PrivacyUsers = []string{"u1"}
+	assert.Nil(t, moment.Validate())
+	moment.Privacy = MomentPrivacyPublic
+	assert.Equal(t, []string{"privacy_users"}, fieldErrors(moment.Validate()))
+
+	metafield := &Metafield{Resource: "r", ResourceId: "r1", Key: "k", Type: MetafieldValueTypeInteger, Value: 1}
+	assert.Nil(t, metafield.Validate())
+	var decoded Metafield
+	assert.Nil(t, json.Unmarshal([]byte(`{"resource":"r","resource_id":"r1","key":"k","type":"integer","value":1.5}`), &decoded))
+	assert.Equal(t, []string{"value"}, fieldErrors(decoded.Validate()))
+	metafield.Type = MetafieldValueTypeDateTime
+	metafield.Value = "2023-01-02T15:04:05Z"
+	assert.Nil(t, metafield.Validate())
+	metafield.Type = MetafieldValueTypeJsonMap
+	assert.Equal(t, []string{"value"}, fieldErrors(metafield.Validate()))
+	metafield.Type = "unknown"
+	assert.Equal(t, []string{"type"}, fieldErrors(metafield.Validate()))
+
+	update := &GroupMemberUpdate{GroupId: "g1"}
+	assert.Equal(t, []string{"member_id"}, fieldErrors(update.Validate()))
+	update.MemberId = "m1"
+	assert.Nil(t, update.Validate())
+}
+
+func TestValidateRevokedMessage(t *testing.T) {
+	message := &Message{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u1", Type: MessageTypeImage}
+	assert.Equal(t, []string{"image"}, fieldErrors(message.Validate()))
+	message.Revoked = true
+	assert.Nil(t, message.Validate())
+	message.Account = ""
+	assert.Equal(t, []string{"account"}, fieldErrors(message.Validate()))
+}
+
+func TestValidateResponses(t *testing.T) {
+	sent := &SendMessageResponse{Message: Message{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "a1", Type: MessageTypeText}}
+	assert.Equal(t, []string{"text"}, fieldErrors(sent.Validate()))
+	forwarded := &ForwardMessageResponse{Messages: []*Message{&sent.Message, nil}}
+	assert.Equal(t, []string{"messages[0].text", "messages[1]"}, fieldErrors(forwarded.Validate()))
+	assert.Equal(t, []string{"reactions[0].emoji"}, fieldErrors((&ReactMessageResponse{Reactions: []*ReactionCount{{Count: 1}}}).Validate()))
+	assert.Equal(t, []string{"user.user_id"}, fieldErrors((&GetChannelInfoResponse{User: &IMUser{}}).Validate()))
+	assert.Nil(t, (&GetChannelInfoResponse{}).Validate())
+
+	moments := &GetMomentListResponse{}
+	moments.Extra.Limit = -1
+	moments.Items = []CursorItem[*Moment]{{Cursor: "1", Item: &Moment{MomentId: "m1", Account: "a1", Type: MomentTypeText}}, {Cursor: "2"}}
+	assert.Equal(t, []string{"extra.limit", "items[0].item.text", "items[1].item"}, fieldErrors(moments.Validate()))
+
+	capabilities := &GetCapabilitiesResponse{Capabilities: Capabilities{
+		MessageTypes:       []MessageType{MessageTypeText, "sticker"},
+		MaxAttachmentSizes: map[MessageType]int64{MessageTypeVideo: -1, "sticker": 1024, MessageTypeImage: 1024},
+	}}
+	assert.Equal(t, []string{"message_types[1]", "max_attachment_sizes.sticker", "max_attachment_sizes.video"}, fieldErrors(capabilities.Validate()))
+}
+
+func TestValidateMessageReference(t *testing.T) {
+	message := &Message{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u1", Type: MessageTypeText, Text: "hello"}
+	message.Reference = &MessageReference{MessageId: "m0", Channel: "c1", Type: MessageTypeImage, Snippet: "[图片]"}
+	assert.Nil(t, message.Validate())
+	message.Reference = &MessageReference{MessageId: "m1", Channel: "c2", Type: "unknown"}
+	assert.Equal(t, []string{"reference.type", "reference.channel", "reference.message_id"}, fieldErrors(message.Validate()))
+}
+
+func TestValidateChatRecord(t *testing.T) {
+	record := &SendMessageRequest{
+		Account: "a1",
+		Channel: "c1",
+		Type:    MessageTypeChatRecord,
+		ChatRecord: &ChatRecordAttachment{Title: "群聊的聊天记录", Messages: []*ChatRecordMessage{
+			{UserId: "u1", Type: MessageTypeText, Text: "hello"},
+			{UserId: "u2", Type: MessageTypeChatRecord, ChatRecord: &ChatRecordAttachment{}},
+		}},
+	}
+	assert.Equal(t, []string{"chat_record.messages[1].chat_record.messages"}, fieldErrors(record.Validate()))
+	record.ChatRecord.Messages[1] = &ChatRecordMessage{UserId: "u2", Type: MessageTypeLink}
+	assert.Equal(t, []string{"chat_record.messages[1].link"}, fieldErrors(record.Validate()))
+	record.ChatRecord.Messages = record.ChatRecord.Messages[:1]
+	assert.Nil(t, record.Validate())
+
+	forward := &ForwardMessageRequest{Account: "a1", Channel: "c1", ToChannel: "c2", Title: "聊天记录"}
+	assert.Equal(t, []string{"message_ids", "title"}, fieldErrors(forward.Validate()))
+	forward.MessageIds = []string{"m1", "m2"}
+	forward.Merge = true
+	assert.Nil(t, forward.Validate())
+}
+
+func TestValidateReaction(t *testing.T) {
+	reaction := &Reaction{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u2"}
+	assert.Equal(t, []string{"emoji"}, fieldErrors(reaction.Validate()))
+	assert.Equal(t, []string{"message_id", "emoji"}, fieldErrors((&ReactMessageRequest{Account: "a1", Channel: "c1"}).Validate()))
+	reacted := &Message{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u1", Type: MessageTypeText, Text: "hello"}
+	reacted.Reactions = []*ReactionCount{{Emoji: "👍", Count: 2, Reacted: true}, {Count: -1}}
+	assert.Equal(t, []string{"reactions[1].emoji", "reactions[1].count"}, fieldErrors(reacted.Validate()))
+}
+
+func TestValidateMessageStatus(t *testing.T) {
+	sent := &Message{MessageId: "m1", Channel: "c1", Account: "a1", UserId: "u1", Type: MessageTypeText, Text: "hello"}
+	sent.Status = MessageStatusFailed
+	assert.Equal(t, []string{"failed_reason"}, fieldErrors(sent.Validate()))
+	sent.FailedReason = "blocked"
+	assert.Nil(t, sent.Validate())
+	sent.Status = MessageStatusSent
+	assert.Equal(t, []string{"failed_reason"}, fieldErrors(sent.Validate()))
+
+	change := &MessageStatusChange{MessageId: "m1", Channel: "c1", Account: "a1"}
+	assert.Equal(t, []string{"status"}, fieldErrors(change.Validate()))
+	change.Receipts = []*MessageReceipt{{UserId: "u2", Status: MessageStatusRead}, {Status: "seen"}}
+	assert.Equal(t, []string{"receipts[1].user_id", "receipts[1].status"}, fieldErrors(change.Validate()))
+	change.Receipts = change.Receipts[:1]
+	assert.Nil(t, change.Validate())
+	assert.Nil(t, (&MarkReadRequest{Account: "a1", Channel: "c1"}).Validate())
+}
+
+func TestValidateMessageCommands(t *testing.T) {
+	edit := &EditMessageRequest{Account: "a1", Channel: "c1", MessageId: "m1"}
+	assert.Equal(t, []string{"text"}, fieldErrors(edit.Validate()))
+	edit.Text = "hello @u2"
+	edit.MentionedUsers = []*MessageMentionedUser{{UserId: "u2", StartAt: 6, EndAt: 9}}
+	assert.Nil(t, edit.Validate())
+	assert.Equal(t, []string{"channel", "message_id"}, fieldErrors((&RevokeMessageRequest{Account: "a1"}).Validate()))
+	assert.Equal(t, []string{"account"}, fieldErrors((&DeleteMessageRequest{Channel: "c1", MessageId: "m1"}).Validate()))
+
+	messageUpdate := &MessageUpdate{MessageId: "m1", EditHistory: []*MessageEdit{{Text: "hello"}}}
+	assert.Equal(t, []string{"edit_history[0].edited_at"}, fieldErrors(messageUpdate.Validate()))
+}
+
+func TestSendEventValidation(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithAuthorization(false),
+		WithEventSource("provider.source/test/test"),
+		WithBaseUrl(server.URL),
+	)
+	err := client.SendEvent(ProviderEventNewAccount, &IMAccount{})
+	assert.NotNil(t, err)
+	assert.Equal(t, InvalidEventDataErrorCode, err.(*ClientError).ErrorCode())
+	assert.Equal(t, "Invalid event data: user_id: is required", err.(*ClientError).Message())
+	assert.Equal(t, 0, calls)
+
+	assert.Nil(t, client.SendEvent(ProviderEventNewAccount, &IMAccount{IMUser: IMUser{UserId: "u1"}}))
+	assert.Equal(t, 1, calls)
+}
+
+func TestCastHandlerValidation(t *testing.T) {
+	client := NewClient(WithEventAuthorization(false))
+	handled := false
+	client.OnEvent(UIMCommandSendMessage, CastCommandHandler(func(_ *cloudevents.Event, _ *SendMessageRequest) (*SendMessageResponse, error) {
+		handled = true
+		return &SendMessageResponse{}, nil
+	}))
+
+	event := cloudevents.NewEvent()
+	event.SetID("e1")
+	event.SetSource("uim")
+	event.SetType(UIMCommandSendMessage)
+	_ = event.SetData(cloudevents.ApplicationJSON, &SendMessageRequest{Account: "a1", Channel: "c1", Type: MessageTypeText})
+	body, _ := json.Marshal(&event)
+
+	recorder := httptest.NewRecorder()
+	client.EventHandler()(recorder, httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(string(body))))
+	assert.Equal(t, InvalidEventDataErrorStatus, recorder.Code)
+	assert.Equal(t, `{"code":"SDK.InvalidEventData","message":"Invalid event data: text: is required"}`, recorder.Body.String())
+	assert.False(t, handled)
+}
+
+func TestCommandResponseValidation(t *testing.T) {
+	client := NewClient(WithEventAuthorization(false))
+	client.OnEvent(UIMCommandSendMessage, CastCommandHandler(func(_ *cloudevents.Event, req *SendMessageRequest) (*SendMessageResponse, error) {
+		return &SendMessageResponse{Message: Message{Channel: req.Channel, Account: req.Account, UserId: req.Account, Type: req.Type, Text: req.Text}}, nil
+	}))
+
+	event := cloudevents.NewEvent()
+	event.SetID("e1")
+	event.SetSource("uim")
+	event.SetType(UIMCommandSendMessage)
+	_ = event.SetData(cloudevents.ApplicationJSON, &SendMessageRequest{Account: "a1", Channel: "c1", Type: MessageTypeText, Text: "hello"})
+	body, _ := json.Marshal(&event)
+
+	recorder := httptest.NewRecorder()
+	client.EventHandler()(recorder, httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(string(body))))
+	assert.Equal(t, InvalidResponseDataErrorStatus, recorder.Code)
+	assert.Equal(t, `{"code":"SDK.InvalidResponseData","message":"Invalid response of \"uim.send_message\": message_id: is required"}`, recorder.Body.String())
+}